@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/kunalsin9h/meltcd/internal/core/application"
 	"github.com/kunalsin9h/meltcd/server"
@@ -105,18 +106,64 @@ func getSpecFromData(cmd *cobra.Command, args []string) (application.Spec, error
 			return application.Spec{}, err
 		}
 
-		path, err := cmd.Flags().GetString("path")
+		paths, err := cmd.Flags().GetStringArray("path")
 		if err != nil {
 			return application.Spec{}, err
 		}
 
+		// first path is the service file, others are override files merged over it
+		path := ""
+		if len(paths) != 0 {
+			path = paths[0]
+		}
+
 		refresh, _ := cmd.Flags().GetString("refresh")
 		revision, _ := cmd.Flags().GetString("revision")
+		destination, _ := cmd.Flags().GetString("destination")
+
+		spec, err = application.ParseSpecFromValue(name, repo, revision, path, refresh, destination)
+		if err != nil {
+			return application.Spec{}, err
+		}
+
+		if len(paths) > 1 {
+			spec.Source.Paths = paths[1:]
+		}
+
+		spec.Target, _ = cmd.Flags().GetString("target")
+		spec.AllowHostPaths, _ = cmd.Flags().GetBool("allow-host-paths")
+		spec.Profiles, _ = cmd.Flags().GetStringArray("profile")
+		spec.BuildRegistry, _ = cmd.Flags().GetString("build-registry")
+
+		trustedKeyFiles, _ := cmd.Flags().GetStringArray("trusted-key")
+		for _, file := range trustedKeyFiles {
+			key, err := os.ReadFile(file)
+			if err != nil {
+				return application.Spec{}, err
+			}
+			spec.TrustedKeys = append(spec.TrustedKeys, string(key))
+		}
+
+		// empty (not nil) trusted keys turn off the verification, nil keeps the current keys
+		if noTrustedKeys, _ := cmd.Flags().GetBool("no-trusted-keys"); noTrustedKeys {
+			if len(spec.TrustedKeys) != 0 {
+				return application.Spec{}, errors.New("--no-trusted-keys can not be used with --trusted-key")
+			}
+			spec.TrustedKeys = []string{}
+		}
+
+		variables, _ := cmd.Flags().GetStringArray("var")
+		spec.Variables, err = util.ParseKeyValues(variables, "variable")
+		if err != nil {
+			return application.Spec{}, err
+		}
 
-		spec, err = application.ParseSpecFromValue(name, repo, revision, path, refresh)
+		params, _ := cmd.Flags().GetStringArray("param")
+		parameters, err := util.ParseKeyValues(params, "parameter")
 		if err != nil {
 			return application.Spec{}, err
 		}
+		spec.Parameters = parameters
 	}
 
 	return spec, nil
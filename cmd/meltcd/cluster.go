@@ -0,0 +1,164 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meltcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kunalsin9h/meltcd/server"
+	"github.com/kunalsin9h/meltcd/server/api/app"
+	"github.com/kunalsin9h/meltcd/server/api/cluster"
+	"github.com/kunalsin9h/meltcd/util"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+func addCluster(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	host, _ := cmd.Flags().GetString("host")
+	tlsSkipVerify, _ := cmd.Flags().GetBool("tls-skip-verify")
+	sshPassphrase, _ := cmd.Flags().GetString("ssh-passphrase")
+	sshInsecure, _ := cmd.Flags().GetBool("ssh-insecure")
+
+	payload := cluster.ClusterDetails{
+		Name:          name,
+		Host:          host,
+		TLSSkipVerify: tlsSkipVerify,
+		SSHPassphrase: sshPassphrase,
+		SSHInsecure:   sshInsecure,
+	}
+
+	// the flags are path to files, the content of the file is send to the server
+	for flag, field := range map[string]*string{
+		"tls-ca":   &payload.TLSCACert,
+		"tls-cert": &payload.TLSCert,
+		"tls-key":  &payload.TLSKey,
+		"ssh-key":  &payload.SSHKey,
+	} {
+		file, _ := cmd.Flags().GetString(flag)
+		if file == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		*field = string(content)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		return err
+	}
+
+	req, client, err := server.HTTPRequestWithBearerToken(http.MethodPost, fmt.Sprintf("%s/api/clusters", util.GetServer()), buf, true)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return server.ReadAuthError(res.Body)
+	}
+
+	var resBody app.GlobalResponse
+	if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+		return err
+	}
+
+	if res.StatusCode != fiber.StatusAccepted {
+		return errors.New(resBody.Message)
+	}
+
+	fmt.Println(resBody.Message)
+	return nil
+}
+
+func getAllClusters(_ *cobra.Command, _ []string) error {
+	req, client, err := server.HTTPRequestWithBearerToken(http.MethodGet, fmt.Sprintf("%s/api/clusters", util.GetServer()), nil, false)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return server.ReadAuthError(res.Body)
+	}
+
+	var resData cluster.ListData
+	if err := json.NewDecoder(res.Body).Decode(&resData); err != nil {
+		return err
+	}
+
+	tbl := table.New("S.NO", "Name", "Host", "Reachable")
+	tbl.WithHeaderFormatter(util.HeaderFmt).WithFirstColumnFormatter(util.ColumnFmt)
+
+	for i, c := range resData.Data {
+		tbl.AddRow(i+1, c.Name, c.Host, c.Reachable)
+	}
+
+	tbl.Print()
+	return nil
+}
+
+func removeCluster(_ *cobra.Command, args []string) error {
+	name := args[0]
+
+	request, client, err := server.HTTPRequestWithBearerToken(http.MethodDelete, fmt.Sprintf("%s/api/clusters/%s", util.GetServer(), name), nil, false)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return server.ReadAuthError(res.Body)
+	}
+
+	var data app.GlobalResponse
+	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return errors.New(data.Message)
+	}
+
+	fmt.Println(data.Message)
+	return nil
+}
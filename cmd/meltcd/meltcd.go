@@ -85,8 +85,16 @@ func NewCLI() *cobra.Command {
 
 	appCreateCmd.Flags().String("repo", "", "The git repository where the service file is hosted")
 	appCreateCmd.Flags().String("revision", "HEAD", "The git repository revision")
-	appCreateCmd.Flags().String("path", "", "The path to service file")
+	appCreateCmd.Flags().StringArray("path", []string{}, "The path to service file (or directory having it), more paths are override files merged in order")
 	appCreateCmd.Flags().String("refresh", "3m0s", "The refresh time for sync")
+	appCreateCmd.Flags().String("destination", "", "The cluster to deploy the application on (default is local)")
+	appCreateCmd.Flags().String("target", "swarm", "The type of docker engine, \"swarm\" or \"docker\" (without swarm mode)")
+	appCreateCmd.Flags().StringArray("trusted-key", []string{}, "Public key (gpg or ssh) file allowed to sign the commits, can be used multiple times")
+	appCreateCmd.Flags().String("build-registry", "", "Registry like ghcr.io/org to build and push the images of services with build")
+	appCreateCmd.Flags().StringArray("profile", []string{}, "Active profile of service file, can be used multiple times")
+	appCreateCmd.Flags().Bool("allow-host-paths", false, "Allow env_file and relative bind mounts from the meltcd host, instead of the repository")
+	appCreateCmd.Flags().StringArray("param", []string{}, "Parameter for service file template like key=value, can be used multiple times")
+	appCreateCmd.Flags().StringArray("var", []string{}, "Variable for interpolation in service file like KEY=value, can be used multiple times")
 	appCreateCmd.Flags().String("file", "", "Application schema file")
 
 	appUpdateCmd := &cobra.Command{
@@ -98,8 +106,17 @@ func NewCLI() *cobra.Command {
 
 	appUpdateCmd.Flags().String("repo", "", "The git repository where the service file is hosted")
 	appUpdateCmd.Flags().String("revision", "HEAD", "The git repository revision")
-	appUpdateCmd.Flags().String("path", "", "The path to service file")
+	appUpdateCmd.Flags().StringArray("path", []string{}, "The path to service file (or directory having it), more paths are override files merged in order")
 	appUpdateCmd.Flags().String("refresh", "3m0s", "The refresh time for sync")
+	appUpdateCmd.Flags().String("destination", "", "The cluster to deploy the application on (default is local)")
+	appUpdateCmd.Flags().String("target", "swarm", "The type of docker engine, \"swarm\" or \"docker\" (without swarm mode)")
+	appUpdateCmd.Flags().StringArray("trusted-key", []string{}, "Public key (gpg or ssh) file allowed to sign the commits, can be used multiple times (default is the current keys)")
+	appUpdateCmd.Flags().Bool("no-trusted-keys", false, "Remove the trusted keys, so commits are deployed without verifying signatures")
+	appUpdateCmd.Flags().String("build-registry", "", "Registry like ghcr.io/org to build and push the images of services with build")
+	appUpdateCmd.Flags().StringArray("profile", []string{}, "Active profile of service file, can be used multiple times")
+	appUpdateCmd.Flags().Bool("allow-host-paths", false, "Allow env_file and relative bind mounts from the meltcd host, instead of the repository")
+	appUpdateCmd.Flags().StringArray("param", []string{}, "Parameter for service file template like key=value, can be used multiple times")
+	appUpdateCmd.Flags().StringArray("var", []string{}, "Variable for interpolation in service file like KEY=value, can be used multiple times")
 	appUpdateCmd.Flags().String("file", "", "Application schema file")
 
 	appGetCmd := &cobra.Command{
@@ -142,6 +159,16 @@ func NewCLI() *cobra.Command {
 		RunE:    app.RecreateApplication,
 	}
 
+	// meltcd app set APP --param replicas=3
+	appSetCmd := &cobra.Command{
+		Use:   "set APP_NAME",
+		Short: "Set parameters of application for its service file template",
+		Args:  cobra.ExactArgs(1),
+		RunE:  app.SetApplicationParameters,
+	}
+
+	appSetCmd.Flags().StringArray("param", []string{}, "Parameter for service file template like key=value, can be used multiple times")
+
 	appCmd.AddCommand(appCreateCmd)
 	appCmd.AddCommand(appUpdateCmd)
 	appCmd.AddCommand(appGetCmd)
@@ -149,9 +176,44 @@ func NewCLI() *cobra.Command {
 	appCmd.AddCommand(appRefreshCmd)
 	appCmd.AddCommand(appRemoveCmd)
 	appCmd.AddCommand(appRecreateCmd)
+	appCmd.AddCommand(appSetCmd)
 
 	rootCmd.AddCommand(appCmd)
 
+	// meltcd validate compose.yaml compose.prod.yaml
+	validateCmd := &cobra.Command{
+		Use:   "validate FILE...",
+		Short: "Validate service files offline, later files are merged over the first",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  validateServiceFiles,
+	}
+
+	validateCmd.Flags().Bool("allow-host-paths", false, "Allow env_file and relative bind mounts from the local filesystem")
+	validateCmd.Flags().StringArray("param", []string{}, "Parameter for service file template like key=value, can be used multiple times")
+	validateCmd.Flags().StringArray("var", []string{}, "Variable for interpolation in service file like KEY=value, can be used multiple times")
+
+	rootCmd.AddCommand(validateCmd)
+
+	// meltcd render -f compose.yaml --app myapp
+	renderCmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the swarm specs generated from service files offline, without server or docker",
+		Args:  cobra.ExactArgs(0),
+		RunE:  renderServiceSpecs,
+	}
+
+	renderCmd.Flags().StringArrayP("file", "f", []string{}, "The service file, more files are override files merged in order")
+	renderCmd.MarkFlagRequired("file")
+	renderCmd.Flags().String("app", "", "The application name, used as the stack namespace")
+	renderCmd.MarkFlagRequired("app")
+	renderCmd.Flags().StringP("output", "o", "yaml", "The output format, \"yaml\" or \"json\"")
+	renderCmd.Flags().StringArray("profile", []string{}, "Active profile of service file, can be used multiple times")
+	renderCmd.Flags().Bool("allow-host-paths", false, "Allow env_file and relative bind mounts from the local filesystem")
+	renderCmd.Flags().StringArray("param", []string{}, "Parameter for service file template like key=value, can be used multiple times")
+	renderCmd.Flags().StringArray("var", []string{}, "Variable for interpolation in service file like KEY=value, can be used multiple times")
+
+	rootCmd.AddCommand(renderCmd)
+
 	// meltcd repo
 	repoCmd := &cobra.Command{
 		Use:     "repo",
@@ -160,6 +222,7 @@ func NewCLI() *cobra.Command {
 	}
 
 	// meltcd  repo add https://github.com/... --username "" --password ""
+	// meltcd  repo add git@github.com:... --ssh-key ~/.ssh/id_ed25519
 	repoAddCmd := &cobra.Command{
 		Use:   "add REPO",
 		Short: "Add a private git repository (--git) or image registry (--image)",
@@ -170,9 +233,11 @@ func NewCLI() *cobra.Command {
 	repoAddCmd.Flags().Bool("git", false, "if private repo is a git repository")
 	repoAddCmd.Flags().Bool("image", false, "if private repo is a docker image")
 	repoAddCmd.Flags().String("username", "", "username for basic auth")
-	repoAddCmd.MarkFlagRequired("username")
 	repoAddCmd.Flags().String("password", "", "password for basic auth")
-	repoAddCmd.MarkFlagRequired("password")
+	repoAddCmd.Flags().String("ssh-key", "", "path to ssh private key, for git repository over ssh")
+	repoAddCmd.Flags().String("ssh-passphrase", "", "passphrase of the ssh private key")
+	repoAddCmd.Flags().String("known-hosts", "", "path to known_hosts file used to verify the git host")
+	repoAddCmd.Flags().Bool("insecure-ignore-host-key", false, "skip verifying the git host key")
 
 	repoListCmd := &cobra.Command{
 		Use:     "list",
@@ -199,9 +264,11 @@ func NewCLI() *cobra.Command {
 	repoUpdateCmd.Flags().Bool("git", false, "if private repo is a git repository")
 	repoUpdateCmd.Flags().Bool("image", false, "if private repo is a docker image")
 	repoUpdateCmd.Flags().String("username", "", "username for basic auth")
-	repoUpdateCmd.MarkFlagRequired("username")
 	repoUpdateCmd.Flags().String("password", "", "password for basic auth")
-	repoUpdateCmd.MarkFlagRequired("password")
+	repoUpdateCmd.Flags().String("ssh-key", "", "path to ssh private key, for git repository over ssh")
+	repoUpdateCmd.Flags().String("ssh-passphrase", "", "passphrase of the ssh private key")
+	repoUpdateCmd.Flags().String("known-hosts", "", "path to known_hosts file used to verify the git host")
+	repoUpdateCmd.Flags().Bool("insecure-ignore-host-key", false, "skip verifying the git host key")
 
 	repoCmd.AddCommand(repoAddCmd)
 	repoCmd.AddCommand(repoListCmd)
@@ -210,5 +277,50 @@ func NewCLI() *cobra.Command {
 
 	rootCmd.AddCommand(repoCmd)
 
+	// meltcd cluster
+	clusterCmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Working with remote docker swarm clusters (deploy targets)",
+	}
+
+	// meltcd cluster add NAME --host tcp://... --tls-ca ... --tls-cert ... --tls-key ...
+	clusterAddCmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "Add a remote cluster with tcp:// or ssh:// host",
+		Args:  cobra.ExactArgs(1), // the cluster name
+		RunE:  addCluster,
+	}
+
+	clusterAddCmd.Flags().String("host", "", "docker host of the cluster, like tcp://HOST:2376 or ssh://USER@HOST")
+	clusterAddCmd.MarkFlagRequired("host")
+	clusterAddCmd.Flags().String("tls-ca", "", "path to the CA certificate (tcp://)")
+	clusterAddCmd.Flags().String("tls-cert", "", "path to the client certificate (tcp://)")
+	clusterAddCmd.Flags().String("tls-key", "", "path to the client key (tcp://)")
+	clusterAddCmd.Flags().Bool("tls-skip-verify", false, "skip verifying the server certificate, not recommended (tcp://)")
+	clusterAddCmd.Flags().String("ssh-key", "", "path to the ssh private key (ssh://)")
+	clusterAddCmd.Flags().String("ssh-passphrase", "", "passphrase of the ssh private key (ssh://)")
+	clusterAddCmd.Flags().Bool("ssh-insecure", false, "skip known_hosts verification of the host (ssh://)")
+
+	clusterListCmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List all added clusters",
+		RunE:    getAllClusters,
+	}
+
+	clusterRemoveCmd := &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "Remove a cluster",
+		Args:    cobra.ExactArgs(1), // the cluster name
+		RunE:    removeCluster,
+	}
+
+	clusterCmd.AddCommand(clusterAddCmd)
+	clusterCmd.AddCommand(clusterListCmd)
+	clusterCmd.AddCommand(clusterRemoveCmd)
+
+	rootCmd.AddCommand(clusterCmd)
+
 	return rootCmd
 }
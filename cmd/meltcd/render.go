@@ -0,0 +1,198 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meltcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/kunalsin9h/meltcd/spec"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// rendered is what the server would create in swarm for the application
+type rendered struct {
+	Networks []spec.NetworkSpec  `json:"networks"`
+	Volumes  []renderedVolume    `json:"volumes"`
+	Services []swarm.ServiceSpec `json:"services"`
+}
+
+type renderedVolume struct {
+	Name    string               `json:"name"`
+	Options *mount.VolumeOptions `json:"options,omitempty"`
+}
+
+// renderServiceSpecs prints the swarm specs of service files without server and docker,
+// the ids of networks, secrets and configs are not known so their names are used instead.
+func renderServiceSpecs(cmd *cobra.Command, _ []string) error {
+	files, _ := cmd.Flags().GetStringArray("file")
+	appName, _ := cmd.Flags().GetString("app")
+	output, _ := cmd.Flags().GetString("output")
+
+	if output != "yaml" && output != "json" {
+		return fmt.Errorf("invalid output %q, must be yaml or json", output)
+	}
+
+	// the logs of making the specs are not part of the output
+	log.SetOutput(io.Discard)
+
+	swarmSpec, loader, err := loadLocalSpec(cmd, files)
+	if err != nil {
+		return err
+	}
+
+	swarmSpec.Files = localFiles{dir: path.Dir(files[0])}
+	swarmSpec.AllowHostPaths, _ = cmd.Flags().GetBool("allow-host-paths")
+	swarmSpec.Profiles, _ = cmd.Flags().GetStringArray("profile")
+
+	out, err := renderSpecs(swarmSpec, appName)
+	if err != nil {
+		return loader.Locate(err)
+	}
+
+	content, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if output == "yaml" {
+		content, err = jsonToYAML(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = os.Stdout.Write(append(content, '\n'))
+	return err
+}
+
+// renderSpecs makes the specs of application like the server does
+func renderSpecs(swarmSpec spec.DockerSwarm, appName string) (rendered, error) {
+	networks, err := swarmSpec.GetNetworkSpecs(appName, "overlay")
+	if err != nil {
+		return rendered{}, err
+	}
+
+	networkIDs := make(map[string]string, len(networks))
+	for _, n := range networks {
+		networkIDs[n.Key] = n.Name
+	}
+
+	objects := spec.Objects{
+		Secrets: objectRefs(appName, swarmSpec.Secrets),
+		Configs: objectRefs(appName, swarmSpec.Configs),
+	}
+
+	services, err := swarmSpec.GetServiceSpec(appName, networkIDs, objects)
+	if err != nil {
+		return rendered{}, err
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	return rendered{
+		Networks: networks,
+		Volumes:  volumeSpecs(services),
+		Services: services,
+	}, nil
+}
+
+// objectRefs names the secrets and configs like the server, without the hash of content
+func objectRefs(appName string, defs map[string]spec.Object) map[string]spec.ObjectRef {
+	refs := make(map[string]spec.ObjectRef, len(defs))
+
+	for key, def := range defs {
+		name := appName + "_" + key
+		if def.External {
+			name = def.Name
+			if name == "" {
+				name = key
+			}
+		}
+
+		refs[key] = spec.ObjectRef{ID: name, Name: name}
+	}
+
+	return refs
+}
+
+// volumeSpecs collects the volumes mounted by the services, swarm creates them on the nodes
+// with the volume options of the mount
+func volumeSpecs(services []swarm.ServiceSpec) []renderedVolume {
+	seen := map[string]bool{}
+	specs := []renderedVolume{}
+
+	for _, service := range services {
+		if service.TaskTemplate.ContainerSpec == nil {
+			continue
+		}
+
+		for _, m := range service.TaskTemplate.ContainerSpec.Mounts {
+			if m.Type != mount.TypeVolume || m.Source == "" || seen[m.Source] {
+				continue
+			}
+			seen[m.Source] = true
+
+			specs = append(specs, renderedVolume{Name: m.Source, Options: m.VolumeOptions})
+		}
+	}
+
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+
+	return specs
+}
+
+// jsonToYAML keeps the order of keys, json is parsed as yaml and printed in block style
+func jsonToYAML(content []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, err
+	}
+
+	var blockStyle func(n *yaml.Node)
+	blockStyle = func(n *yaml.Node) {
+		n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+		for _, c := range n.Content {
+			blockStyle(c)
+		}
+	}
+	blockStyle(&node)
+
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
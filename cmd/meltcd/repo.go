@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -38,12 +39,10 @@ func addPrivateRepository(cmd *cobra.Command, args []string) error {
 
 	git, _ := cmd.Flags().GetBool("git")
 	image, _ := cmd.Flags().GetBool("image")
-	username, _ := cmd.Flags().GetString("username")
-	password, _ := cmd.Flags().GetString("password")
 
-	payload := repo.PrivateRepoDetails{
-		Username: username,
-		Password: password,
+	payload, err := getRepoCredentials(cmd)
+	if err != nil {
+		return err
 	}
 
 	// if not git then if image is also false, then default is git
@@ -87,6 +86,46 @@ func addPrivateRepository(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// getRepoCredentials reads the basic auth or ssh key flags,
+// ssh key and known_hosts are read from the files and send as content
+func getRepoCredentials(cmd *cobra.Command) (repo.PrivateRepoDetails, error) {
+	username, _ := cmd.Flags().GetString("username")
+	password, _ := cmd.Flags().GetString("password")
+	sshKeyFile, _ := cmd.Flags().GetString("ssh-key")
+	sshPassphrase, _ := cmd.Flags().GetString("ssh-passphrase")
+	knownHostsFile, _ := cmd.Flags().GetString("known-hosts")
+	insecure, _ := cmd.Flags().GetBool("insecure-ignore-host-key")
+
+	if sshKeyFile == "" && (username == "" || password == "") {
+		return repo.PrivateRepoDetails{}, errors.New("either --ssh-key or --username and --password are required")
+	}
+
+	payload := repo.PrivateRepoDetails{
+		Username:              username,
+		Password:              password,
+		SSHPassphrase:         sshPassphrase,
+		InsecureIgnoreHostKey: insecure,
+	}
+
+	if sshKeyFile != "" {
+		key, err := os.ReadFile(sshKeyFile)
+		if err != nil {
+			return repo.PrivateRepoDetails{}, err
+		}
+		payload.SSHKey = string(key)
+	}
+
+	if knownHostsFile != "" {
+		knownHosts, err := os.ReadFile(knownHostsFile)
+		if err != nil {
+			return repo.PrivateRepoDetails{}, err
+		}
+		payload.KnownHosts = string(knownHosts)
+	}
+
+	return payload, nil
+}
+
 func getAllRepoAdded(_ *cobra.Command, _ []string) error {
 	req, client, err := server.HTTPRequestWithBearerToken(http.MethodGet, fmt.Sprintf("%s/api/repo", util.GetServer()), nil, false)
 	if err != nil {
@@ -173,8 +212,11 @@ func updatePrivateRepo(cmd *cobra.Command, args []string) error {
 
 	git, _ := cmd.Flags().GetBool("git")
 	image, _ := cmd.Flags().GetBool("image")
-	username, _ := cmd.Flags().GetString("username")
-	password, _ := cmd.Flags().GetString("password")
+
+	payload, err := getRepoCredentials(cmd)
+	if err != nil {
+		return err
+	}
 
 	gitRepo, imageRepo := "", ""
 
@@ -186,12 +228,8 @@ func updatePrivateRepo(cmd *cobra.Command, args []string) error {
 		imageRepo = repoName
 	}
 
-	payload := repo.PrivateRepoDetails{
-		URL:      gitRepo,
-		ImageRef: imageRepo,
-		Username: username,
-		Password: password,
-	}
+	payload.URL = gitRepo
+	payload.ImageRef = imageRepo
 
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(payload); err != nil {
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meltcd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kunalsin9h/meltcd/spec"
+	"github.com/kunalsin9h/meltcd/util"
+	"github.com/spf13/cobra"
+)
+
+// localFiles reads the files of service file from the local filesystem, relative to dir
+type localFiles struct {
+	dir string
+}
+
+func (f localFiles) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(f.dir, filepath.FromSlash(name)))
+}
+
+// validateServiceFiles loads the service files and makes the specs of all the services,
+// like render does with every profile active
+func validateServiceFiles(cmd *cobra.Command, args []string) error {
+	swarmSpec, loader, err := loadLocalSpec(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	// the logs of making the specs are not part of the output
+	log.SetOutput(io.Discard)
+
+	swarmSpec.Files = localFiles{dir: path.Dir(args[0])}
+	swarmSpec.AllowHostPaths, _ = cmd.Flags().GetBool("allow-host-paths")
+	swarmSpec.Profiles = []string{"*"}
+
+	if _, err := renderSpecs(swarmSpec, "validate"); err != nil {
+		return loader.Locate(err)
+	}
+
+	fmt.Printf("%s is valid\n", strings.Join(args, ", "))
+	return nil
+}
+
+// loadLocalSpec merges the local service files like the server does for the repository,
+// the variables are from the ".env" file next to the first file and the --var flags,
+// the template values from the "values.yaml" file next to the first file and the --param flags.
+func loadLocalSpec(cmd *cobra.Command, paths []string) (spec.DockerSwarm, *spec.Loader, error) {
+	for i, p := range paths {
+		paths[i] = filepath.ToSlash(p)
+	}
+
+	variables := map[string]string{}
+
+	dotEnv, err := os.ReadFile(filepath.Join(filepath.Dir(paths[0]), ".env"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return spec.DockerSwarm{}, nil, err
+	}
+
+	if err == nil {
+		variables, err = spec.ParseDotEnv(string(dotEnv))
+		if err != nil {
+			return spec.DockerSwarm{}, nil, fmt.Errorf(".env: %w", err)
+		}
+	}
+
+	vars, _ := cmd.Flags().GetStringArray("var")
+	flagVariables, err := util.ParseKeyValues(vars, "variable")
+	if err != nil {
+		return spec.DockerSwarm{}, nil, err
+	}
+
+	for key, value := range flagVariables {
+		variables[key] = value
+	}
+
+	values := map[string]interface{}{}
+
+	valuesFile, err := os.ReadFile(filepath.Join(filepath.Dir(paths[0]), "values.yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return spec.DockerSwarm{}, nil, err
+	}
+
+	if err == nil {
+		values, err = spec.ParseValues(valuesFile)
+		if err != nil {
+			return spec.DockerSwarm{}, nil, fmt.Errorf("values.yaml: %w", err)
+		}
+	}
+
+	params, _ := cmd.Flags().GetStringArray("param")
+	parameters, err := util.ParseKeyValues(params, "parameter")
+	if err != nil {
+		return spec.DockerSwarm{}, nil, err
+	}
+
+	for key, value := range parameters {
+		if err := spec.SetValue(values, key, value); err != nil {
+			return spec.DockerSwarm{}, nil, err
+		}
+	}
+
+	loader := spec.Loader{
+		Files: localFiles{},
+		Lookup: func(name string) (string, bool) {
+			value, found := variables[name]
+			return value, found
+		},
+		Values: values,
+	}
+
+	swarmSpec, err := loader.Load(paths)
+	if err != nil {
+		return spec.DockerSwarm{}, nil, err
+	}
+
+	for _, warning := range loader.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning.Error())
+	}
+
+	return swarmSpec, &loader, nil
+}
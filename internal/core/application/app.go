@@ -20,38 +20,48 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"strings"
+	"fmt"
+	"path"
 	"time"
 
 	"log/slog"
 
+	"github.com/kunalsin9h/meltcd/internal/core/cluster"
 	"github.com/kunalsin9h/meltcd/internal/core/repository"
 	"github.com/kunalsin9h/meltcd/spec"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/swarm"
-	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	billy "github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
-	"gopkg.in/yaml.v2"
 )
 
 type Application struct {
-	ID           uint32        `json:"id"`
-	Name         string        `json:"name"`
-	Source       Source        `json:"source"`
-	RefreshTimer string        `json:"refresh_timer"` // Timer to check for Sync format of "3m50s"
-	Health       Health        `json:"health"`
-	HealthStatus string        `json:"health_status"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
-	LastSyncedAt time.Time     `json:"last_synced_at"`
-	LiveState    string        `json:"-"`
-	SyncTrigger  chan SyncType `json:"-"`
+	ID             uint32            `json:"id"`
+	Name           string            `json:"name"`
+	Source         Source            `json:"source"`
+	RefreshTimer   string            `json:"refresh_timer"`    // Timer to check for Sync format of "3m50s"
+	Destination    string            `json:"destination"`      // Cluster where the application is deployed
+	Target         string            `json:"target"`           // Type of the destination "swarm" (default) or "docker"
+	TrustedKeys    []string          `json:"trusted_keys"`     // Keys allowed to sign the deployed commits
+	Variables      map[string]string `json:"variables"`        // Variables for interpolation in service file
+	AllowHostPaths bool              `json:"allow_host_paths"` // Allow files from meltcd host, not only from repository
+	Profiles       []string          `json:"profiles"`         // Active profiles of service file
+	BuildRegistry  string            `json:"build_registry"`   // Registry for images built from repository
+	Parameters     map[string]string `json:"parameters"`       // Values for service file templates
+	Health         Health            `json:"health"`
+	HealthStatus   string            `json:"health_status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	LastSyncedAt   time.Time         `json:"last_synced_at"`
+	SyncError      string            `json:"sync_error"` // Reason of the last failed sync
+	LiveState      string            `json:"-"`
+	SyncedCommit   string            `json:"synced_commit"` // Commit of the repository which is deployed
+	SyncTrigger    chan SyncType     `json:"-"`
 }
 
 type Health int
@@ -78,6 +88,12 @@ func (h Health) ToString() string {
 	return "NA"
 }
 
+// Target is the type of docker engine the application is deployed on
+const (
+	TargetSwarm  = "swarm"
+	TargetDocker = "docker" // docker engine without swarm mode
+)
+
 type SyncType int
 
 const (
@@ -87,9 +103,17 @@ const (
 
 func New(spec Spec) Application {
 	return Application{
-		Name:         spec.Name,
-		RefreshTimer: spec.RefreshTimer,
-		Source:       spec.Source,
+		Name:           spec.Name,
+		RefreshTimer:   spec.RefreshTimer,
+		Source:         spec.Source,
+		Destination:    spec.Destination,
+		Target:         spec.Target,
+		TrustedKeys:    spec.TrustedKeys,
+		Variables:      spec.Variables,
+		AllowHostPaths: spec.AllowHostPaths,
+		Profiles:       spec.Profiles,
+		BuildRegistry:  spec.BuildRegistry,
+		Parameters:     spec.Parameters,
 	}
 }
 
@@ -113,6 +137,7 @@ func (app *Application) Run() {
 		if err := updateTicker(app.RefreshTimer, ticker); err != nil {
 			slog.Error(err.Error())
 			app.Health = Degraded
+			app.SyncError = err.Error()
 			continue
 		}
 
@@ -121,13 +146,15 @@ func (app *Application) Run() {
 			slog.Warn("Not able to get service", "repo", app.Source.RepoURL)
 			slog.Error(err.Error())
 			app.Health = Degraded
+			app.SyncError = err.Error()
 			continue
 		}
 		slog.Info("got target state")
 		if app.SyncStatus(targetState) {
 			// TODO: Sync Status = Synched
 			slog.Info("Synched")
-			app.Health = Healthy
+			app.Health = app.liveHealth()
+			app.SyncError = ""
 			continue
 		}
 		slog.Info("liveState and Target state is out of sync. syncing now...")
@@ -136,11 +163,13 @@ func (app *Application) Run() {
 		app.Health = Progressing
 		if err := app.Apply(targetState); err != nil {
 			app.Health = Degraded
+			app.SyncError = err.Error()
 			slog.Warn("Not able to apply targetState", "error", err.Error())
 			continue
 		}
 
-		app.Health = Healthy
+		app.Health = app.liveHealth()
+		app.SyncError = ""
 		slog.Info("Applied new changes")
 	}
 }
@@ -163,7 +192,15 @@ func updateTicker(duration string, t *time.Ticker) error {
 	return nil
 }
 
-func (app *Application) GetState() (string, error) {
+// TargetState is the state of the application in the git repository
+type TargetState struct {
+	Content string           // content of the service files
+	Paths   []string         // service files in order of merge
+	Commit  string           // commit hash of the target revision
+	Files   billy.Filesystem // checkout of the repository, to read files referenced by service file
+}
+
+func (app *Application) GetState() (TargetState, error) {
 	slog.Info("Getting service state from git repo", "repo", app.Source.RepoURL, "app_name", app.Name)
 	// TODO: not using targetRevision
 
@@ -176,7 +213,10 @@ func (app *Application) GetState() (string, error) {
 	// defer clear storage, i (kunal singh) think that when storage goes out-of-scope
 	// it is cleared
 
-	username, password := repository.FindCreds(app.Source.RepoURL)
+	auth, err := repository.GetGitAuth(app.Source.RepoURL)
+	if err != nil {
+		return TargetState{}, err
+	}
 
 	// TODO: Improvement
 	// GET the name and commit also
@@ -186,15 +226,12 @@ func (app *Application) GetState() (string, error) {
 		ref = plumbing.NewBranchReferenceName(app.Source.TargetRevision)
 	}
 
-	_, err := git.Clone(storage, fs, &git.CloneOptions{
+	repo, err := git.Clone(storage, fs, &git.CloneOptions{
 		URL:           app.Source.RepoURL,
 		ReferenceName: ref,
 		SingleBranch:  true,
 		Depth:         1,
-		Auth: &http.BasicAuth{
-			Username: username,
-			Password: password,
-		},
+		Auth:          auth,
 	})
 
 	// if errors.Is(err, git.ErrRepositoryAlreadyExists) {
@@ -204,64 +241,93 @@ func (app *Application) GetState() (string, error) {
 	// 	slog.Error("Since the storage is not persistent, this error should not exist")
 	// } else
 	if err != nil {
-		return "", err
+		return TargetState{}, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return TargetState{}, err
+	}
+
+	if len(app.TrustedKeys) != 0 {
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return TargetState{}, err
+		}
+
+		if err := verifyCommit(commit, app.TrustedKeys); err != nil {
+			slog.Error("Commit signature verification failed", "repo", app.Source.RepoURL, "commit", head.Hash().String())
+			return TargetState{}, err
+		}
+		slog.Info("Verified commit signature", "commit", head.Hash().String())
 	}
 
-	serviceFile, err := fs.Open(app.Source.Path)
+	paths, err := app.servicePaths(fs)
 	if err != nil {
 		slog.Error("Path not found", "repo", app.Source.RepoURL, "path", app.Source.Path)
-		return "", err
+		return TargetState{}, err
 	}
-	defer serviceFile.Close()
 
-	// reading the service file content
+	// reading the service files content
 	buf := new(bytes.Buffer)
-	buf.ReadFrom(serviceFile)
+	for _, p := range paths {
+		serviceFile, err := fs.Open(p)
+		if err != nil {
+			return TargetState{}, err
+		}
+
+		buf.ReadFrom(serviceFile)
+		serviceFile.Close()
+	}
 
-	return buf.String(), nil
+	return TargetState{
+		Content: buf.String(),
+		Paths:   paths,
+		Commit:  head.Hash().String(),
+		Files:   fs,
+	}, nil
 }
 
-func (app *Application) Apply(targetState string) error {
+func (app *Application) Apply(targetState TargetState) error {
 	slog.Info("Applying new targetState")
 	// TODO this client can be stored i app or new struct core
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+	cli, err := cluster.NewClient(app.Destination)
 	if err != nil {
-		slog.Error("Not able to create a new docker client")
+		slog.Error("Not able to create a new docker client", "destination", app.Destination)
 		return err
 	}
+	defer cli.Close()
 
-	var swarmSpec spec.DockerSwarm
-	if err := yaml.Unmarshal([]byte(targetState), &swarmSpec); err != nil {
+	swarmSpec, err := app.loadSpec(targetState)
+	if err != nil {
 		return err
 	}
 
-	// TODO use volOpts
-	// create volume
-	for volName, volOpts := range swarmSpec.Volumes {
-		labels := make(map[string]string)
-		for _, l := range volOpts.Labels {
-			tokens := strings.SplitN(l, "=", 2)
-			if len(tokens) != 2 {
-				return errors.New("invalid labels in volume")
-			}
+	if err := app.buildImages(cli, &swarmSpec, targetState); err != nil {
+		return err
+	}
 
-			labels[tokens[0]] = tokens[1]
+	if app.Target == TargetDocker {
+		if err := app.applyStandalone(cli, &swarmSpec); err != nil {
+			return err
 		}
 
-		cli.VolumeCreate(context.Background(), volume.CreateOptions{
-			Name:       volName,
-			Driver:     volOpts.Driver,
-			DriverOpts: volOpts.DriverOpts,
-			Labels:     labels, // TODO labels are not working
-		})
+		app.LiveState = targetState.Content
+		app.SyncedCommit = targetState.Commit
+		return nil
 	}
 
-	networkID, err := createNetwork(cli, app.Name)
+	networkIDs, err := createNetworks(cli, app.Name, &swarmSpec, "overlay")
 	if err != nil {
 		return err
 	}
 
-	services, err := swarmSpec.GetServiceSpec(app.Name, networkID)
+	objects, err := app.createObjects(cli, &swarmSpec)
+	if err != nil {
+		return err
+	}
+
+	services, err := swarmSpec.GetServiceSpec(app.Name, networkIDs, objects)
 	if err != nil {
 		return err
 	}
@@ -337,16 +403,93 @@ func (app *Application) Apply(targetState string) error {
 		app.LastSyncedAt = time.Now()
 	}
 
-	app.LiveState = targetState
+	if err := removeOrphanServices(cli, app.Name, services, allServicesRunning); err != nil {
+		return err
+	}
+
+	app.removeOldObjects(cli, objects)
+
+	app.LiveState = targetState.Content
+	app.SyncedCommit = targetState.Commit
 	return nil
 }
 
+// loadSpec merges the service files of target state, with templates rendered and variables interpolated.
+// Files referenced by service files are read relative to the first service file.
+func (app *Application) loadSpec(targetState TargetState) (spec.DockerSwarm, error) {
+	if targetState.Files == nil || len(targetState.Paths) == 0 {
+		return spec.DockerSwarm{}, errors.New("service files are not fetched from repository")
+	}
+
+	dir := path.Dir(targetState.Paths[0])
+
+	lookup, err := app.lookupVariables(targetState.Files, dir)
+	if err != nil {
+		return spec.DockerSwarm{}, err
+	}
+
+	values, err := app.templateValues(targetState.Files, dir)
+	if err != nil {
+		return spec.DockerSwarm{}, err
+	}
+
+	loader := spec.Loader{
+		Files:  repoFiles{fs: targetState.Files},
+		Lookup: lookup,
+		Values: values,
+	}
+
+	swarmSpec, err := loader.Load(targetState.Paths)
+	if err != nil {
+		return spec.DockerSwarm{}, err
+	}
+
+	for _, warning := range loader.Warnings {
+		slog.Warn("Service file has unsupported key", "app_name", app.Name, "warning", warning.Error())
+	}
+
+	swarmSpec.Files = repoFiles{fs: targetState.Files, dir: dir}
+	swarmSpec.AllowHostPaths = app.AllowHostPaths
+	swarmSpec.Profiles = app.Profiles
+
+	return swarmSpec, nil
+}
+
 // SyncStatus Check if LiveState = TargetState
 //
 // Whether or not the live state matches the target state.
 // Is the deployed application the same as Git says it should be?
-func (app *Application) SyncStatus(targetState string) bool {
-	return app.LiveState == targetState
+func (app *Application) SyncStatus(targetState TargetState) bool {
+	return app.LiveState == targetState.Content && app.SyncedCommit == targetState.Commit
+}
+
+// removeOrphanServices removes the services of application which are not in the spec anymore,
+// like services removed from the service file or whose profile is not active.
+func removeOrphanServices(cli *client.Client, appName string, services []swarm.ServiceSpec, running []swarm.Service) error {
+	for _, svc := range running {
+		if svc.Spec.Labels["com.docker.stack.namespace"] != appName {
+			continue
+		}
+
+		orphan := true
+		for _, service := range services {
+			if service.Name == svc.Spec.Name {
+				orphan = false
+				break
+			}
+		}
+
+		if !orphan {
+			continue
+		}
+
+		slog.Info("Removing service not in the spec", "name", svc.Spec.Name)
+		if err := cli.ServiceRemove(context.Background(), svc.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func checkServiceAlreadyExist(serviceName string, allServices *[]swarm.Service) (swarm.Service, bool) {
@@ -358,39 +501,58 @@ func checkServiceAlreadyExist(serviceName string, allServices *[]swarm.Service)
 	return swarm.Service{}, false
 }
 
-func createNetwork(cli *client.Client, appName string) (string, error) {
-	slog.Info("Creating network")
-	networkName := appName + "_default"
+// createNetworks creates the networks of the application, with overlay driver
+// for swarm and bridge for standalone docker. External networks are only looked up.
+// It returns the network IDs keyed by their name in service file.
+func createNetworks(cli *client.Client, appName string, swarmSpec *spec.DockerSwarm, driver string) (map[string]string, error) {
+	slog.Info("Creating networks")
+
+	networks, err := swarmSpec.GetNetworkSpecs(appName, driver)
+	if err != nil {
+		return nil, err
+	}
 
 	nets, err := cli.NetworkList(context.Background(), types.NetworkListOptions{})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	for _, network := range nets {
-		if network.Name == networkName {
-			slog.Info("Network already exists")
-			return network.ID, nil
+	ids := make(map[string]string, len(networks))
+
+	for _, n := range networks {
+		if id, found := findNetwork(n.Name, nets); found {
+			slog.Info("Network already exists", "name", n.Name)
+			ids[n.Key] = id
+			continue
 		}
-	}
 
-	net, err := cli.NetworkCreate(context.Background(), networkName, types.NetworkCreate{
-		Scope: "swarm",
-		Labels: map[string]string{
-			"com.docker.stack.namespace": appName,
-		},
-		Driver: "overlay",
-	})
+		if n.External {
+			return nil, fmt.Errorf("external network %s does not exists", n.Name)
+		}
+
+		net, err := cli.NetworkCreate(context.Background(), n.Name, n.Options)
+		if err != nil {
+			return nil, err
+		}
 
-	slog.Info("Created network", "id", net.ID)
+		slog.Info("Created network", "name", n.Name, "id", net.ID)
 
-	if err != nil {
-		return "", err
+		if net.Warning != "" {
+			slog.Warn(net.Warning)
+		}
+
+		ids[n.Key] = net.ID
 	}
 
-	if net.Warning != "" {
-		slog.Warn(net.Warning)
+	return ids, nil
+}
+
+func findNetwork(name string, nets []types.NetworkResource) (string, bool) {
+	for _, network := range nets {
+		if network.Name == name {
+			return network.ID, true
+		}
 	}
 
-	return net.ID, nil
+	return "", false
 }
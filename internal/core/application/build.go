@@ -0,0 +1,279 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kunalsin9h/meltcd/internal/core/repository"
+	"github.com/kunalsin9h/meltcd/spec"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	billy "github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// buildImages builds the images of services having build from the repository, when the
+// application has a build registry. Images are tagged with the commit and pushed to the
+// registry, then used as the image of service. Images already in the registry for the commit
+// are reused, images built before but not pushed (like after a failed push) are pushed again.
+func (app *Application) buildImages(cli *client.Client, swarmSpec *spec.DockerSwarm, targetState TargetState) error {
+	for name, svc := range swarmSpec.Services {
+		if svc.Build == nil {
+			continue
+		}
+
+		if app.BuildRegistry == "" {
+			if svc.Image == "" {
+				return fmt.Errorf("service %s: has build without image, set build registry of application to build it", name)
+			}
+
+			slog.Info("Build registry is not set, using image of service", "service", name, "image", svc.Image)
+			continue
+		}
+
+		ref := getBuildRef(app.BuildRegistry, app.Name, name, targetState.Commit)
+
+		auth, err := registryAuth(ref, app.BuildRegistry)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+
+		if _, err := cli.DistributionInspect(context.Background(), ref, auth); err == nil {
+			slog.Info("Image already pushed for the commit", "service", name, "image", ref)
+		} else {
+			if _, _, err := cli.ImageInspectWithRaw(context.Background(), ref); err == nil {
+				slog.Info("Image already built for the commit", "service", name, "image", ref)
+			} else if err := app.buildImage(cli, swarmSpec, svc.Build, ref, targetState); err != nil {
+				return fmt.Errorf("service %s: %w", name, err)
+			}
+
+			if err := pushImage(cli, ref, auth); err != nil {
+				return fmt.Errorf("service %s: %w", name, err)
+			}
+		}
+
+		svc.Image = ref
+		swarmSpec.Services[name] = svc
+	}
+
+	return nil
+}
+
+// getBuildRef returns the image like "registry/app-service:commit"
+func getBuildRef(registry, appName, serviceName, commit string) string {
+	return strings.TrimSuffix(registry, "/") + "/" + strings.ToLower(appName+"-"+serviceName) + ":" + commit
+}
+
+func (app *Application) buildImage(cli *client.Client, swarmSpec *spec.DockerSwarm, build *spec.Build, ref string, targetState TargetState) error {
+	if targetState.Files == nil || len(targetState.Paths) == 0 {
+		return fmt.Errorf("build needs the files of repository")
+	}
+
+	contextDir := path.Join(path.Dir(targetState.Paths[0]), build.Context)
+	if contextDir == ".." || strings.HasPrefix(contextDir, "../") || path.IsAbs(build.Context) {
+		return fmt.Errorf("build context %s is outside of the repository", build.Context)
+	}
+
+	buildContext, err := tarDirectory(targetState.Files, contextDir, build.Dockerfile)
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{}
+	for k, v := range build.Labels {
+		labels[k] = v
+	}
+	labels["com.docker.stack.namespace"] = app.Name
+
+	slog.Info("Building image", "image", ref, "context", contextDir)
+	res, err := cli.ImageBuild(context.Background(), buildContext, types.ImageBuildOptions{
+		Tags:       []string{ref},
+		Dockerfile: build.Dockerfile,
+		BuildArgs:  swarmSpec.GetBuildArgs(build),
+		Target:     build.Target,
+		Labels:     labels,
+		Remove:     true,
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// the build errors are in the stream
+	return jsonmessage.DisplayJSONMessagesStream(res.Body, io.Discard, 0, false, nil)
+}
+
+// registryAuth returns the credentials of the image, of the build registry like "host:5000/org"
+// or of the registry host, empty when none of them is added.
+func registryAuth(ref, registry string) (string, error) {
+	candidates := []string{ref, registry}
+	if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+		candidates = append(candidates, reference.Domain(named))
+	}
+
+	for _, name := range candidates {
+		if repo, found := repository.FindRepo(name); found {
+			return repo.GetRegistryAuth()
+		}
+	}
+
+	slog.Warn("Registry credentials not found, using the registry without auth", "image", ref)
+	return "", nil
+}
+
+func pushImage(cli *client.Client, ref, auth string) error {
+	slog.Info("Pushing image", "image", ref)
+	out, err := cli.ImagePush(context.Background(), ref, types.ImagePushOptions{
+		RegistryAuth: auth,
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return jsonmessage.DisplayJSONMessagesStream(out, io.Discard, 0, false, nil)
+}
+
+// tarDirectory makes the build context from directory of repository, without the files
+// matching the .dockerignore of directory like the docker cli. The Dockerfile and
+// .dockerignore are always in the context, since the builder reads them.
+func tarDirectory(fs billy.Filesystem, dir, dockerfile string) (io.Reader, error) {
+	excludes, err := readDockerignore(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	excludes = append(excludes, "!"+path.Clean(dockerfile), "!.dockerignore")
+
+	matcher, err := patternmatcher.New(excludes)
+	if err != nil {
+		return nil, fmt.Errorf(".dockerignore: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+
+	err = util.Walk(fs, dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := filePath
+		if dir != "." {
+			name = strings.TrimPrefix(strings.TrimPrefix(filePath, dir), "/")
+		}
+
+		if name == "" || name == "." {
+			return nil
+		}
+
+		if info.IsDir() && (name == ".git" || strings.HasSuffix(name, "/.git")) {
+			return filepath.SkipDir
+		}
+
+		excluded, err := matcher.MatchesOrParentMatches(filepath.FromSlash(name))
+		if err != nil {
+			return err
+		}
+
+		if excluded {
+			// files of excluded directory can be included again, like "!docs/README.md"
+			if info.IsDir() && !matcher.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := fs.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			header.Linkname = target
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := fs.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// readDockerignore reads the patterns of .dockerignore in directory, none when there is no file
+func readDockerignore(fs billy.Filesystem, dir string) ([]string, error) {
+	f, err := fs.Open(path.Join(dir, ".dockerignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	excludes, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf(".dockerignore: %w", err)
+	}
+
+	return excludes, nil
+}
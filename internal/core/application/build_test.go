@@ -0,0 +1,77 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+func TestTarDirectoryDockerignore(t *testing.T) {
+	fs := memfs.New()
+
+	for name, content := range map[string]string{
+		"app/Dockerfile":         "FROM scratch",
+		"app/.dockerignore":      "# build output\nnode_modules\n*.log\ndocs\n!docs/README.md\nDockerfile\n",
+		"app/main.go":            "package main",
+		"app/debug.log":          "log",
+		"app/node_modules/a.js":  "a",
+		"app/docs/guide.md":      "guide",
+		"app/docs/README.md":     "readme",
+		"app/.git/HEAD":          "ref",
+		"app/src/server.go":      "package src",
+		"other/not-in-context.x": "x",
+	} {
+		if err := util.WriteFile(fs, name, []byte(content), 0644); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	context, err := tarDirectory(fs, "app", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var files []string
+	tr := tar.NewReader(context)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			files = append(files, header.Name)
+		}
+	}
+	sort.Strings(files)
+
+	expected := []string{".dockerignore", "Dockerfile", "docs/README.md", "main.go", "src/server.go"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("wrong build context files\nexpected %v\ngot %v", expected, files)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	billy "github.com/go-git/go-billy/v5"
+)
+
+// repoFiles reads the files referenced by service file from the repository,
+// relative to the directory of service file and never outside the repository.
+type repoFiles struct {
+	fs  billy.Filesystem
+	dir string
+}
+
+func (r repoFiles) ReadFile(name string) ([]byte, error) {
+	if path.IsAbs(name) {
+		return nil, fmt.Errorf("file %s must be relative to the service file", name)
+	}
+
+	filePath := path.Join(r.dir, name)
+	if filePath == ".." || strings.HasPrefix(filePath, "../") {
+		return nil, fmt.Errorf("file %s is outside of the repository", name)
+	}
+
+	f, err := r.fs.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// default service files in directory, like docker compose
+var serviceFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// servicePaths returns the service files of application in order of merge, when path
+// is a directory the default service file and its override file in it are used.
+func (app *Application) servicePaths(fs billy.Filesystem) ([]string, error) {
+	info, err := fs.Stat(app.Source.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{app.Source.Path}
+
+	if info.IsDir() {
+		paths = nil
+
+		for _, name := range serviceFileNames {
+			file := path.Join(app.Source.Path, name)
+			if _, err := fs.Stat(file); err != nil {
+				continue
+			}
+
+			paths = append(paths, file)
+
+			override := strings.TrimSuffix(file, path.Ext(file)) + ".override" + path.Ext(file)
+			if _, err := fs.Stat(override); err == nil {
+				paths = append(paths, override)
+			}
+			break
+		}
+
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no service file found in directory %s", app.Source.Path)
+		}
+	}
+
+	for _, p := range app.Source.Paths {
+		if _, err := fs.Stat(p); err != nil {
+			return nil, err
+		}
+		paths = append(paths, p)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/kunalsin9h/meltcd/internal/core/cluster"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+)
+
+// liveHealth checks the state of running tasks (or containers for standalone docker),
+// so that the health of application reflects the healthchecks of the containers.
+func (app *Application) liveHealth() Health {
+	cli, err := cluster.NewClient(app.Destination)
+	if err != nil {
+		slog.Error("Not able to create a new docker client", "destination", app.Destination)
+		return Degraded
+	}
+	defer cli.Close()
+
+	var health Health
+	if app.Target == TargetDocker {
+		health, err = containersHealth(cli, app.Name)
+	} else {
+		health, err = servicesHealth(cli, app.Name)
+	}
+
+	if err != nil {
+		slog.Error("Not able to get the live health of application", "app_name", app.Name, "error", err.Error())
+		return Degraded
+	}
+
+	return health
+}
+
+func servicesHealth(cli *client.Client, appName string) (Health, error) {
+	services, err := cli.ServiceList(context.Background(), types.ServiceListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.stack.namespace="+appName)),
+	})
+	if err != nil {
+		return Degraded, err
+	}
+
+	health := Healthy
+
+	for _, svc := range services {
+		tasks, err := cli.TaskList(context.Background(), types.TaskListOptions{
+			Filters: filters.NewArgs(
+				filters.Arg("service", svc.ID),
+				filters.Arg("desired-state", "running"),
+			),
+		})
+		if err != nil {
+			return Degraded, err
+		}
+
+		if len(tasks) == 0 && svc.Spec.Mode.Replicated != nil &&
+			svc.Spec.Mode.Replicated.Replicas != nil && *svc.Spec.Mode.Replicated.Replicas != 0 {
+			health = Progressing
+		}
+
+		for _, task := range tasks {
+			switch task.Status.State {
+			case swarm.TaskStateRunning, swarm.TaskStateComplete:
+			case swarm.TaskStateFailed, swarm.TaskStateRejected, swarm.TaskStateOrphaned:
+				slog.Warn("Task is not running", "service", svc.Spec.Name, "state", task.Status.State, "error", task.Status.Err)
+				return Degraded, nil
+			default:
+				// task is starting, with healthcheck it remains "starting" until healthy
+				health = Progressing
+			}
+		}
+	}
+
+	return health, nil
+}
+
+func containersHealth(cli *client.Client, appName string) (Health, error) {
+	containers, err := cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.stack.namespace="+appName)),
+	})
+	if err != nil {
+		return Degraded, err
+	}
+
+	health := Healthy
+
+	for _, c := range containers {
+		switch {
+		case c.State != "running", strings.Contains(c.Status, "(unhealthy)"):
+			slog.Warn("Container is not healthy", "container", c.Names, "status", c.Status)
+			return Degraded, nil
+		case strings.Contains(c.Status, "(health: starting)"):
+			health = Progressing
+		}
+	}
+
+	return health, nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+
+	"github.com/kunalsin9h/meltcd/spec"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+)
+
+const objectKeyLabel = "com.meltcd.object"
+
+// objectStore is the swarm api for either secrets or configs
+type objectStore struct {
+	kind   string
+	list   func(args filters.Args) ([]objectInfo, error)
+	create func(annotations swarm.Annotations, data []byte) (string, error)
+	remove func(id string) error
+}
+
+// objectInfo is a swarm secret or config
+type objectInfo struct {
+	ID   string
+	Name string
+}
+
+func secretStore(cli *client.Client) objectStore {
+	return objectStore{
+		kind: "secret",
+		list: func(args filters.Args) ([]objectInfo, error) {
+			secrets, err := cli.SecretList(context.Background(), types.SecretListOptions{Filters: args})
+			if err != nil {
+				return nil, err
+			}
+
+			res := make([]objectInfo, 0, len(secrets))
+			for _, s := range secrets {
+				res = append(res, objectInfo{ID: s.ID, Name: s.Spec.Name})
+			}
+			return res, nil
+		},
+		create: func(annotations swarm.Annotations, data []byte) (string, error) {
+			res, err := cli.SecretCreate(context.Background(), swarm.SecretSpec{Annotations: annotations, Data: data})
+			return res.ID, err
+		},
+		remove: func(id string) error {
+			return cli.SecretRemove(context.Background(), id)
+		},
+	}
+}
+
+func configStore(cli *client.Client) objectStore {
+	return objectStore{
+		kind: "config",
+		list: func(args filters.Args) ([]objectInfo, error) {
+			configs, err := cli.ConfigList(context.Background(), types.ConfigListOptions{Filters: args})
+			if err != nil {
+				return nil, err
+			}
+
+			res := make([]objectInfo, 0, len(configs))
+			for _, c := range configs {
+				res = append(res, objectInfo{ID: c.ID, Name: c.Spec.Name})
+			}
+			return res, nil
+		},
+		create: func(annotations swarm.Annotations, data []byte) (string, error) {
+			res, err := cli.ConfigCreate(context.Background(), swarm.ConfigSpec{Annotations: annotations, Data: data})
+			return res.ID, err
+		},
+		remove: func(id string) error {
+			return cli.ConfigRemove(context.Background(), id)
+		},
+	}
+}
+
+// createObjects makes sure the secrets and configs of the service file exist in swarm.
+// Objects are immutable in swarm, so the content hash is part of the name and
+// a new version is created whenever the file in repository changes.
+func (app *Application) createObjects(cli *client.Client, swarmSpec *spec.DockerSwarm) (spec.Objects, error) {
+	var objects spec.Objects
+	var err error
+
+	objects.Secrets, err = app.ensureObjects(secretStore(cli), swarmSpec.Secrets, swarmSpec.Files)
+	if err != nil {
+		return spec.Objects{}, err
+	}
+
+	objects.Configs, err = app.ensureObjects(configStore(cli), swarmSpec.Configs, swarmSpec.Files)
+	if err != nil {
+		return spec.Objects{}, err
+	}
+
+	return objects, nil
+}
+
+func (app *Application) ensureObjects(store objectStore, defs map[string]spec.Object, files spec.FileReader) (map[string]spec.ObjectRef, error) {
+	refs := make(map[string]spec.ObjectRef)
+	if len(defs) == 0 {
+		return refs, nil
+	}
+
+	existing, err := store.list(filters.NewArgs())
+	if err != nil {
+		return nil, err
+	}
+
+	for key, def := range defs {
+		if def.External {
+			name := def.Name
+			if name == "" {
+				name = key
+			}
+
+			obj, found := findObject(name, existing)
+			if !found {
+				return nil, fmt.Errorf("external %s %s does not exists", store.kind, name)
+			}
+
+			refs[key] = spec.ObjectRef{ID: obj.ID, Name: obj.Name}
+			continue
+		}
+
+		if def.File == "" {
+			return nil, fmt.Errorf("%s %s must have file or be external", store.kind, key)
+		}
+
+		if files == nil {
+			return nil, fmt.Errorf("%s %s: repository files are not available", store.kind, key)
+		}
+
+		data, err := files.ReadFile(def.File)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", store.kind, key, err)
+		}
+
+		hash := sha256.Sum256(data)
+		name := app.Name + "_" + key + "-" + hex.EncodeToString(hash[:])[:10]
+
+		if obj, found := findObject(name, existing); found {
+			refs[key] = spec.ObjectRef{ID: obj.ID, Name: obj.Name}
+			continue
+		}
+
+		labels := make(map[string]string)
+		for k, v := range def.Labels {
+			labels[k] = v
+		}
+		labels["com.docker.stack.namespace"] = app.Name
+		labels[objectKeyLabel] = key
+
+		slog.Info("Creating new version of "+store.kind, "name", name)
+		id, err := store.create(swarm.Annotations{Name: name, Labels: labels}, data)
+		if err != nil {
+			return nil, err
+		}
+
+		refs[key] = spec.ObjectRef{ID: id, Name: name}
+	}
+
+	return refs, nil
+}
+
+// removeOldObjects removes the old versions of secrets and configs which are not used anymore,
+// objects still used by running tasks can not be removed and are tried again on next sync.
+func (app *Application) removeOldObjects(cli *client.Client, objects spec.Objects) {
+	removeUnused(secretStore(cli), app.Name, objects.Secrets)
+	removeUnused(configStore(cli), app.Name, objects.Configs)
+}
+
+// RemoveObjects removes all the secrets and configs created for the application
+func RemoveObjects(cli *client.Client, appName string) {
+	removeUnused(secretStore(cli), appName, nil)
+	removeUnused(configStore(cli), appName, nil)
+}
+
+func removeUnused(store objectStore, appName string, inUse map[string]spec.ObjectRef) {
+	owned, err := store.list(filters.NewArgs(
+		filters.Arg("label", "com.docker.stack.namespace="+appName),
+		filters.Arg("label", objectKeyLabel),
+	))
+	if err != nil {
+		slog.Error("Failed to list "+store.kind+"s", "error", err.Error())
+		return
+	}
+
+	used := make(map[string]bool)
+	for _, ref := range inUse {
+		used[ref.ID] = true
+	}
+
+	for _, obj := range owned {
+		if used[obj.ID] {
+			continue
+		}
+
+		if err := store.remove(obj.ID); err != nil {
+			slog.Warn("Failed to remove old "+store.kind, "name", obj.Name, "error", err.Error())
+			continue
+		}
+		slog.Info("Removed old "+store.kind, "name", obj.Name)
+	}
+}
+
+func findObject(name string, objects []objectInfo) (objectInfo, bool) {
+	for _, obj := range objects {
+		if obj.Name == name {
+			return obj, true
+		}
+	}
+
+	return objectInfo{}, false
+}
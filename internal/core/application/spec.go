@@ -29,12 +29,33 @@ type Spec struct {
 	Name         string `json:"name" yaml:"name"`
 	RefreshTimer string `json:"refresh_timer" yaml:"refresh_timer"` // number of minutes
 	Source       Source `json:"source" yaml:"source"`
+	Destination  string `json:"destination" yaml:"destination"` // name of the cluster, empty means local
+	Target       string `json:"target" yaml:"target"`           // "swarm" (default) or "docker" for engine without swarm mode
+	// Public keys (armored gpg or ssh) allowed to sign the commits,
+	// when set unsigned or untrusted commits are not deployed
+	TrustedKeys []string `json:"trusted_keys" yaml:"trusted_keys"`
+	// Variables used for interpolation in service file like "${TAG:-latest}",
+	// they take precedence over the ".env" file next to service file
+	Variables map[string]string `json:"variables" yaml:"variables"`
+	// Allow env_file and relative bind mounts from the filesystem of meltcd host,
+	// by default files are only read from the repository
+	AllowHostPaths bool `json:"allow_host_paths" yaml:"allow_host_paths"`
+	// Active profiles of service file, services with other profiles are not deployed
+	Profiles []string `json:"profiles" yaml:"profiles"`
+	// Registry like "ghcr.io/org" to push the images built for services with build,
+	// when empty services with build use their image
+	BuildRegistry string `json:"build_registry" yaml:"build_registry"`
+	// Parameters for the service files which are go templates like "compose.yaml.tmpl",
+	// they take precedence over the "values.yaml" next to service file
+	Parameters map[string]string `json:"parameters" yaml:"parameters"`
 }
 
 type Source struct {
 	RepoURL        string `json:"repoURL" yaml:"repoURL"`
 	TargetRevision string `json:"targetRevision" yaml:"targetRevision"`
-	Path           string `json:"path" yaml:"path"`
+	Path           string `json:"path" yaml:"path"` // service file or directory having it
+	// Paths are the override service files merged over Path in order, like "docker-compose.prod.yml"
+	Paths []string `json:"paths" yaml:"paths"`
 }
 
 // parse an application from yaml source
@@ -64,7 +85,7 @@ func ParseSpecFromFile(file string) (Spec, error) {
 	return spec, nil
 }
 
-func ParseSpecFromValue(name, repo, revision, path, refresh string) (Spec, error) {
+func ParseSpecFromValue(name, repo, revision, path, refresh, destination string) (Spec, error) {
 	if repo == "" {
 		return Spec{}, errors.New("the git repository not specified")
 	}
@@ -76,6 +97,7 @@ func ParseSpecFromValue(name, repo, revision, path, refresh string) (Spec, error
 	return Spec{
 		Name:         name,
 		RefreshTimer: refresh,
+		Destination:  destination,
 		Source: Source{
 			RepoURL:        repo,
 			TargetRevision: revision,
@@ -0,0 +1,216 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/kunalsin9h/meltcd/internal/core/repository"
+	"github.com/kunalsin9h/meltcd/spec"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+const specHashLabel = "com.meltcd.spec-hash"
+
+// applyStandalone deploys the services as plain containers, for docker engines without
+// swarm mode. Containers are only recreated when their spec is changed.
+func (app *Application) applyStandalone(cli *client.Client, swarmSpec *spec.DockerSwarm) error {
+	networkIDs, err := createNetworks(cli, app.Name, swarmSpec, "bridge")
+	if err != nil {
+		return err
+	}
+
+	containers, err := swarmSpec.GetContainerSpec(app.Name, networkIDs)
+	if err != nil {
+		return err
+	}
+	slog.Info("Get containers from the source schema", "number of containers found", len(containers))
+
+	running, err := cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.stack.namespace="+app.Name)),
+	})
+	if err != nil {
+		return err
+	}
+
+	// containers which are not in the spec anymore are removed at the end
+	orphans := make(map[string]string)
+	for _, c := range running {
+		if len(c.Names) != 0 {
+			orphans[c.Names[0][1:]] = c.ID
+		}
+	}
+
+	for _, cs := range containers {
+		hash, err := getSpecHash(&cs)
+		if err != nil {
+			return err
+		}
+		cs.Config.Labels[specHashLabel] = hash
+
+		if err := pullImage(cli, cs.Config.Image); err != nil {
+			return err
+		}
+
+		for i := uint64(1); i <= cs.Replicas; i++ {
+			name := fmt.Sprintf("%s_%d", cs.Name, i)
+			delete(orphans, name)
+
+			if c, exists := findContainer(name, running); exists {
+				if c.Labels[specHashLabel] == hash {
+					slog.Info("Container already up to date", "name", name)
+					continue
+				}
+
+				slog.Info("Container spec changed, recreating", "name", name)
+				if err := cli.ContainerRemove(context.Background(), c.ID, container.RemoveOptions{Force: true}); err != nil {
+					return err
+				}
+			}
+
+			if err := createContainer(cli, name, cs); err != nil {
+				app.Health = Degraded
+				slog.Error("Not able to create a new container", "error", err.Error())
+				return err
+			}
+
+			app.LastSyncedAt = time.Now()
+		}
+	}
+
+	for name, id := range orphans {
+		slog.Info("Removing container not in the spec", "name", name)
+		if err := cli.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func createContainer(cli *client.Client, name string, cs spec.ContainerSpec) error {
+	slog.Info("Creating new container", "name", name)
+
+	// a container can only be created with one network, others are connected
+	// after it is created, in the order of service networks for every sync
+	networks := cs.Networks
+
+	networkingConfig := *cs.NetworkingConfig
+	if len(networks) > 1 {
+		networkingConfig.EndpointsConfig = map[string]*network.EndpointSettings{
+			networks[0]: cs.NetworkingConfig.EndpointsConfig[networks[0]],
+		}
+	}
+
+	res, err := cli.ContainerCreate(context.Background(), cs.Config, cs.HostConfig, &networkingConfig, nil, name)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Warnings) != 0 {
+		slog.Warn("New Container create give warnings", "warnings", res.Warnings)
+	}
+
+	if len(networks) > 1 {
+		for _, id := range networks[1:] {
+			if err := cli.NetworkConnect(context.Background(), id, res.ID, cs.NetworkingConfig.EndpointsConfig[id]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return cli.ContainerStart(context.Background(), res.ID, container.StartOptions{})
+}
+
+// pullImage pulls the image before creating containers, since unlike swarm, docker
+// does not pull images on container create. When the pull fails the image on the host
+// is used, like on an offline host or for an image built on the host.
+func pullImage(cli *client.Client, image string) error {
+	err := pull(cli, image)
+	if err == nil {
+		return nil
+	}
+
+	if _, _, inspectErr := cli.ImageInspectWithRaw(context.Background(), image); inspectErr == nil {
+		slog.Warn("Failed to pull docker image, using the image on host", "image", image, "error", err.Error())
+		return nil
+	}
+
+	slog.Error("Failed to pull docker image", "image", image)
+	return err
+}
+
+func pull(cli *client.Client, image string) error {
+	auth := ""
+
+	repo, found := repository.FindRepo(image)
+	if found {
+		authString, err := repo.GetRegistryAuth()
+		if err != nil {
+			slog.Error(err.Error())
+		} else {
+			auth = authString
+		}
+	}
+
+	out, err := cli.ImagePull(context.Background(), image, types.ImagePullOptions{
+		RegistryAuth: auth,
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// wait for the pull to complete, the pull errors are in the stream
+	return jsonmessage.DisplayJSONMessagesStream(out, io.Discard, 0, false, nil)
+}
+
+func findContainer(name string, containers []types.Container) (types.Container, bool) {
+	for _, c := range containers {
+		for _, n := range c.Names {
+			// container names are prefixed with "/"
+			if n == "/"+name {
+				return c, true
+			}
+		}
+	}
+	return types.Container{}, false
+}
+
+func getSpecHash(cs *spec.ContainerSpec) (string, error) {
+	d, err := json.Marshal(cs)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(d)
+	return hex.EncodeToString(hash[:]), nil
+}
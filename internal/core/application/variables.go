@@ -0,0 +1,93 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/kunalsin9h/meltcd/spec"
+
+	billy "github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// lookupVariables returns the variables for interpolation of service files, the application
+// variables take precedence over the ".env" file in dir of repository.
+func (app *Application) lookupVariables(files billy.Filesystem, dir string) (func(string) (string, bool), error) {
+	variables, err := readDotEnv(files, path.Join(dir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range app.Variables {
+		variables[k] = v
+	}
+
+	return func(name string) (string, bool) {
+		value, found := variables[name]
+		return value, found
+	}, nil
+}
+
+// templateValues returns the values for service file templates, the application
+// parameters take precedence over the "values.yaml" file in dir of repository.
+func (app *Application) templateValues(files billy.Filesystem, dir string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	content, err := util.ReadFile(files, path.Join(dir, "values.yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		values, err = spec.ParseValues(content)
+		if err != nil {
+			return nil, fmt.Errorf("values.yaml: %w", err)
+		}
+	}
+
+	for k, v := range app.Parameters {
+		if err := spec.SetValue(values, k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// readDotEnv returns no variables when the file does not exist
+func readDotEnv(files billy.Filesystem, file string) (map[string]string, error) {
+	f, err := files.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(f); err != nil {
+		return nil, err
+	}
+
+	return spec.ParseDotEnv(buf.String())
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+const pgpPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+const sshSignatureHeader = "-----BEGIN SSH SIGNATURE-----"
+const sshSignatureFooter = "-----END SSH SIGNATURE-----"
+
+// verifyCommit checks that the commit is signed by one of the trusted keys.
+// trusted keys are armored GPG public keys or ssh public keys (authorized_keys format)
+func verifyCommit(commit *object.Commit, trustedKeys []string) error {
+	if commit.PGPSignature == "" {
+		return fmt.Errorf("commit %s is not signed", commit.Hash)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(commit.PGPSignature), sshSignatureHeader) {
+		return verifySSHSignature(commit, trustedKeys)
+	}
+
+	for _, key := range trustedKeys {
+		if !strings.HasPrefix(strings.TrimSpace(key), pgpPublicKeyHeader) {
+			continue
+		}
+
+		if _, err := commit.Verify(key); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("commit %s is not signed by a trusted gpg key", commit.Hash)
+}
+
+// sshSignature is the signature blob created by "ssh-keygen -Y sign"
+// see https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+type sshSignature struct {
+	Magic         [6]byte
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+type sshSignedData struct {
+	Magic         [6]byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+func verifySSHSignature(commit *object.Commit, trustedKeys []string) error {
+	sig, err := parseSSHSignature(commit.PGPSignature)
+	if err != nil {
+		return fmt.Errorf("commit %s has invalid ssh signature: %w", commit.Hash, err)
+	}
+
+	signer, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	if !isTrustedSSHKey(signer, trustedKeys) {
+		return fmt.Errorf("commit %s is not signed by a trusted ssh key", commit.Hash)
+	}
+
+	message, err := encodeWithoutSignature(commit)
+	if err != nil {
+		return err
+	}
+
+	var hash []byte
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h := sha256.Sum256(message)
+		hash = h[:]
+	case "sha512":
+		h := sha512.Sum512(message)
+		hash = h[:]
+	default:
+		return fmt.Errorf("unsupported ssh signature hash algorithm: %s", sig.HashAlgorithm)
+	}
+
+	signedData := ssh.Marshal(sshSignedData{
+		Magic:         sig.Magic,
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          hash,
+	})
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		return err
+	}
+
+	if err := signer.Verify(signedData, &signature); err != nil {
+		return fmt.Errorf("commit %s has bad ssh signature: %w", commit.Hash, err)
+	}
+
+	return nil
+}
+
+func parseSSHSignature(armored string) (*sshSignature, error) {
+	armored = strings.TrimSpace(armored)
+	armored, _ = strings.CutPrefix(armored, sshSignatureHeader)
+	armored, _ = strings.CutSuffix(armored, sshSignatureFooter)
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(armored), ""))
+	if err != nil {
+		return nil, err
+	}
+
+	var sig sshSignature
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return nil, err
+	}
+
+	if string(sig.Magic[:]) != "SSHSIG" || sig.Version != 1 {
+		return nil, errors.New("not a ssh signature")
+	}
+
+	if sig.Namespace != "git" {
+		return nil, fmt.Errorf("signature namespace is %q not \"git\"", sig.Namespace)
+	}
+
+	return &sig, nil
+}
+
+func isTrustedSSHKey(signer ssh.PublicKey, trustedKeys []string) bool {
+	for _, key := range trustedKeys {
+		if strings.HasPrefix(strings.TrimSpace(key), pgpPublicKeyHeader) {
+			continue
+		}
+
+		trusted, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil {
+			continue
+		}
+
+		if bytes.Equal(trusted.Marshal(), signer.Marshal()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func encodeWithoutSignature(commit *object.Commit) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return nil, err
+	}
+
+	r, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
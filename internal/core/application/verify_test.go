@@ -0,0 +1,97 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// the fixtures are commits made by git, signed with gpg and "gpg.format ssh",
+// except ssh_namespace.commit which is signed by "ssh-keygen -Y sign -n file"
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "signatures", name))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return string(data)
+}
+
+func decodeCommit(t *testing.T, raw string) *object.Commit {
+	t.Helper()
+
+	obj := &plumbing.MemoryObject{}
+	obj.SetType(plumbing.CommitObject)
+	if _, err := obj.Write([]byte(raw)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	commit := &object.Commit{}
+	if err := commit.Decode(obj); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return commit
+}
+
+func TestVerifyCommit(t *testing.T) {
+	trustedGPG := readFixture(t, "trusted_gpg.asc")
+	trustedSSH := readFixture(t, "trusted_ssh.pub")
+	untrustedSSH := readFixture(t, "untrusted_ssh.pub")
+
+	gpgCommit := readFixture(t, "gpg.commit")
+	sshCommit := readFixture(t, "ssh.commit")
+
+	tests := []struct {
+		name        string
+		commit      string
+		trustedKeys []string
+		err         string // empty when the commit must be verified
+	}{
+		{"trusted gpg key", gpgCommit, []string{trustedSSH, trustedGPG}, ""},
+		{"trusted ssh key", sshCommit, []string{trustedGPG, trustedSSH}, ""},
+		{"untrusted ssh key", sshCommit, []string{trustedGPG, untrustedSSH}, "not signed by a trusted ssh key"},
+		{"untrusted gpg key", gpgCommit, []string{trustedSSH, untrustedSSH}, "not signed by a trusted gpg key"},
+		{"unsigned commit", readFixture(t, "unsigned.commit"), []string{trustedGPG, trustedSSH}, "is not signed"},
+		{"wrong ssh namespace", readFixture(t, "ssh_namespace.commit"), []string{trustedSSH}, `namespace is "file"`},
+		{"changed gpg commit", strings.Replace(gpgCommit, "signed with gpg", "changed", 1), []string{trustedGPG}, "not signed by a trusted gpg key"},
+		{"changed ssh commit", strings.Replace(sshCommit, "signed with ssh", "changed", 1), []string{trustedSSH}, "bad ssh signature"},
+	}
+
+	for _, test := range tests {
+		err := verifyCommit(decodeCommit(t, test.commit), test.trustedKeys)
+
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: commit is not verified: %s", test.name, err.Error())
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected error %q, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"log/slog"
+)
+
+// Cluster is a remote docker swarm (or docker engine) which
+// applications can use as their deploy target (destination)
+type Cluster struct {
+	Name, Host string
+	// PEM encoded TLS certificates, stored in base64
+	// used when the Host is tcp://
+	TLSCACert, TLSCert, TLSKey string
+	TLSSkipVerify              bool
+	// Private key (base64) and its passphrase for ssh:// hosts
+	SSHKey, SSHPassphrase string
+	SSHInsecure           bool
+	Reachable             bool
+}
+
+// Credentials for connecting to a cluster, all the keys
+// and certificates are PEM encoded
+type Credentials struct {
+	TLSCACert, TLSCert, TLSKey string
+	TLSSkipVerify              bool
+	SSHKey, SSHPassphrase      string
+	SSHInsecure                bool
+}
+
+var clusters []*Cluster
+
+func (c *Cluster) saveCredentials(creds Credentials) {
+	c.TLSCACert = base64.StdEncoding.EncodeToString([]byte(creds.TLSCACert))
+	c.TLSCert = base64.StdEncoding.EncodeToString([]byte(creds.TLSCert))
+	c.TLSKey = base64.StdEncoding.EncodeToString([]byte(creds.TLSKey))
+	c.TLSSkipVerify = creds.TLSSkipVerify
+	c.SSHKey = base64.StdEncoding.EncodeToString([]byte(creds.SSHKey))
+	c.SSHPassphrase = base64.StdEncoding.EncodeToString([]byte(creds.SSHPassphrase))
+	c.SSHInsecure = creds.SSHInsecure
+}
+
+func (c *Cluster) getCredentials() (Credentials, error) {
+	var creds Credentials
+
+	for _, field := range []struct {
+		from string
+		to   *string
+	}{
+		{c.TLSCACert, &creds.TLSCACert},
+		{c.TLSCert, &creds.TLSCert},
+		{c.TLSKey, &creds.TLSKey},
+		{c.SSHKey, &creds.SSHKey},
+		{c.SSHPassphrase, &creds.SSHPassphrase},
+	} {
+		d, err := base64.StdEncoding.DecodeString(field.from)
+		if err != nil {
+			return Credentials{}, err
+		}
+		*field.to = string(d)
+	}
+
+	creds.TLSSkipVerify = c.TLSSkipVerify
+	creds.SSHInsecure = c.SSHInsecure
+
+	return creds, nil
+}
+
+func (c *Cluster) checkReachability() {
+	cli, err := c.newClient()
+	if err != nil {
+		slog.Error("Not able to create docker client for cluster", "cluster", c.Name, "error", err.Error())
+		c.Reachable = false
+		return
+	}
+	defer cli.Close()
+
+	if _, err := cli.Ping(context.Background()); err != nil {
+		slog.Error("Cluster is not reachable", "cluster", c.Name, "error", err.Error())
+		c.Reachable = false
+		return
+	}
+
+	c.Reachable = true
+}
+
+// Add registers a new cluster with name and docker host
+// host can be tcp://HOST:PORT or ssh://USER@HOST
+func Add(name, host string, creds Credentials) error {
+	if name == "" || name == LocalCluster {
+		return errors.New("invalid cluster name")
+	}
+
+	if _, found := FindCluster(name); found {
+		return errors.New("cluster with same name already exists")
+	}
+
+	if !strings.HasPrefix(host, "tcp://") && !strings.HasPrefix(host, "ssh://") {
+		return errors.New("cluster host must start with tcp:// or ssh://")
+	}
+
+	cluster := &Cluster{
+		Name:      name,
+		Host:      host,
+		Reachable: true,
+	}
+	cluster.saveCredentials(creds)
+
+	go cluster.checkReachability()
+
+	clusters = append(clusters, cluster)
+	return nil
+}
+
+func GetData() ([]byte, error) {
+	result, err := json.Marshal(clusters)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return result, nil
+}
+
+func LoadData(d *[]byte) error {
+	return json.Unmarshal(*d, &clusters)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/client"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// LocalCluster is the docker daemon meltcd is running with, it is
+// used when the application does not specify any destination.
+const LocalCluster = "local"
+
+// NewClient creates a docker client for the destination cluster,
+// if the destination is empty the local docker daemon is used
+func NewClient(destination string) (*client.Client, error) {
+	if destination == "" || destination == LocalCluster {
+		return client.NewClientWithOpts(client.FromEnv)
+	}
+
+	c, found := FindCluster(destination)
+	if !found {
+		return nil, errors.New("destination cluster does not exists: " + destination)
+	}
+
+	return c.newClient()
+}
+
+func (c *Cluster) newClient() (*client.Client, error) {
+	creds, err := c.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	hostURL, err := url.Parse(c.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	switch hostURL.Scheme {
+	case "tcp":
+		tlsConfig, err := getTLSConfig(&creds)
+		if err != nil {
+			return nil, err
+		}
+
+		httpClient := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig,
+			},
+		}
+
+		return client.NewClientWithOpts(
+			client.WithHTTPClient(httpClient),
+			client.WithHost(c.Host),
+			client.WithAPIVersionNegotiation(),
+		)
+	case "ssh":
+		dialer, err := getSSHDialer(hostURL, &creds)
+		if err != nil {
+			return nil, err
+		}
+
+		// the host does not matter here, every connection is made using the ssh dialer
+		return client.NewClientWithOpts(
+			client.WithHTTPClient(&http.Client{
+				Transport: &http.Transport{
+					DialContext: dialer,
+				},
+			}),
+			client.WithHost("http://docker.example.com"),
+			client.WithDialContext(dialer),
+			client.WithAPIVersionNegotiation(),
+		)
+	}
+
+	return nil, errors.New("unsupported cluster host scheme: " + hostURL.Scheme)
+}
+
+// getTLSConfig returns nil when no certificates are given,
+// that means plain tcp connection. The server certificate is verified
+// with the CA certificate (or the system CAs) unless TLSSkipVerify is set.
+func getTLSConfig(creds *Credentials) (*tls.Config, error) {
+	if creds.TLSCACert == "" && creds.TLSCert == "" && creds.TLSKey == "" && !creds.TLSSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: creds.TLSSkipVerify, //nolint:gosec
+	}
+
+	if creds.TLSCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(creds.TLSCACert)) {
+			return nil, errors.New("failed to parse tls ca certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if creds.TLSCert != "" || creds.TLSKey != "" {
+		cert, err := tls.X509KeyPair([]byte(creds.TLSCert), []byte(creds.TLSKey))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// getSSHDialer returns a dialer which opens a ssh connection to the host and
+// runs "docker system dial-stdio" on it, like the docker cli does for ssh:// hosts.
+func getSSHDialer(hostURL *url.URL, creds *Credentials) (func(context.Context, string, string) (net.Conn, error), error) {
+	if creds.SSHKey == "" {
+		return nil, errors.New("ssh private key is required for ssh:// clusters")
+	}
+
+	var signer ssh.Signer
+	var err error
+
+	if creds.SSHPassphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(creds.SSHKey), []byte(creds.SSHPassphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(creds.SSHKey))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey() //nolint:gosec
+	if !creds.SSHInsecure {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+
+		hostKeyCallback, err = knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	username := hostURL.User.Username()
+	if username == "" {
+		username = "root"
+	}
+
+	addr := hostURL.Host
+	if hostURL.Port() == "" {
+		addr = net.JoinHostPort(hostURL.Hostname(), "22")
+	}
+
+	config := &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}
+
+	return func(_ context.Context, _, _ string) (net.Conn, error) {
+		sshClient, err := ssh.Dial("tcp", addr, config)
+		if err != nil {
+			return nil, err
+		}
+
+		session, err := sshClient.NewSession()
+		if err != nil {
+			sshClient.Close()
+			return nil, err
+		}
+
+		stdin, err := session.StdinPipe()
+		if err != nil {
+			sshClient.Close()
+			return nil, err
+		}
+
+		stdout, err := session.StdoutPipe()
+		if err != nil {
+			sshClient.Close()
+			return nil, err
+		}
+
+		if err := session.Start("docker system dial-stdio"); err != nil {
+			sshClient.Close()
+			return nil, err
+		}
+
+		return &sshConn{
+			Reader:  stdout,
+			stdin:   stdin,
+			session: session,
+			client:  sshClient,
+		}, nil
+	}, nil
+}
+
+// sshConn is the net.Conn over the stdin and stdout
+// of "docker system dial-stdio" running on remote host
+type sshConn struct {
+	io.Reader
+	stdin   io.WriteCloser
+	session *ssh.Session
+	client  *ssh.Client
+}
+
+func (c *sshConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *sshConn) Close() error {
+	c.stdin.Close()
+	c.session.Close()
+	return c.client.Close()
+}
+
+func (c *sshConn) LocalAddr() net.Addr {
+	return c.client.LocalAddr()
+}
+
+func (c *sshConn) RemoteAddr() net.Addr {
+	return c.client.RemoteAddr()
+}
+
+func (c *sshConn) SetDeadline(_ time.Time) error {
+	return nil
+}
+
+func (c *sshConn) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+func (c *sshConn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
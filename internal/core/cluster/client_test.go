@@ -0,0 +1,76 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestGetTLSConfig(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "meltcd test ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	config, err := getTLSConfig(&Credentials{})
+	if err != nil || config != nil {
+		t.Errorf("tls must not be used without certificates: %v %v", config, err)
+	}
+
+	config, err = getTLSConfig(&Credentials{TLSCACert: ca})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if config.InsecureSkipVerify || config.RootCAs == nil {
+		t.Error("server certificate must be verified with the ca certificate")
+	}
+
+	config, err = getTLSConfig(&Credentials{TLSCACert: ca, TLSSkipVerify: true})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !config.InsecureSkipVerify {
+		t.Error("server certificate must not be verified with TLSSkipVerify")
+	}
+
+	if _, err := getTLSConfig(&Credentials{TLSCACert: "not a certificate"}); err == nil {
+		t.Error("invalid ca certificate must be an error")
+	}
+}
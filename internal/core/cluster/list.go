@@ -0,0 +1,37 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+type ClusterData struct {
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+	Reachable bool   `json:"reachable"`
+}
+
+func List() []ClusterData {
+	res := make([]ClusterData, 0)
+
+	for _, c := range clusters {
+		res = append(res, ClusterData{
+			Name:      c.Name,
+			Host:      c.Host,
+			Reachable: c.Reachable,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import "errors"
+
+func Remove(name string) error {
+	if _, found := FindCluster(name); !found {
+		return errors.New("cluster does not exists")
+	}
+
+	tmp := make([]*Cluster, 0)
+
+	for _, c := range clusters {
+		if c.Name != name {
+			tmp = append(tmp, c)
+		}
+	}
+
+	clusters = tmp
+	return nil
+}
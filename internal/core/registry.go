@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/kunalsin9h/meltcd/internal/core/application"
+	"github.com/kunalsin9h/meltcd/internal/core/cluster"
 
 	"log/slog"
 )
@@ -40,6 +43,14 @@ func Register(app *application.Application) error {
 		return fmt.Errorf("app already exists with name: %s", app.Name)
 	}
 
+	if err := checkDestination(app.Destination); err != nil {
+		return err
+	}
+
+	if err := checkTarget(app.Target); err != nil {
+		return err
+	}
+
 	app.SyncTrigger = make(chan application.SyncType, 1)
 
 	timeOfCreation := time.Now()
@@ -67,8 +78,31 @@ func Update(app *application.Application) error {
 		return fmt.Errorf("app does not exists, create a new application first")
 	}
 
+	if err := checkDestination(app.Destination); err != nil {
+		return err
+	}
+
+	if err := checkTarget(app.Target); err != nil {
+		return err
+	}
+
 	runningApp.RefreshTimer = app.RefreshTimer
 	runningApp.Source = app.Source
+	runningApp.Destination = app.Destination
+	runningApp.Target = app.Target
+	// trusted keys are only changed when specified, so an update does not turn off the verification
+	if app.TrustedKeys != nil {
+		runningApp.TrustedKeys = app.TrustedKeys
+	}
+	runningApp.Variables = app.Variables
+	runningApp.AllowHostPaths = app.AllowHostPaths
+	runningApp.Profiles = app.Profiles
+	runningApp.BuildRegistry = app.BuildRegistry
+	// parameters are replaced like variables, SetParameters merges them
+	runningApp.Parameters = app.Parameters
+
+	// clearing the current state, so that new settings are applied
+	runningApp.LiveState = ""
 
 	runningApp.UpdatedAt = time.Now()
 
@@ -78,6 +112,32 @@ func Update(app *application.Application) error {
 	return nil
 }
 
+// SetParameters sets the parameters of application for its service file templates,
+// other parameters are kept as they are.
+func SetParameters(appName string, parameters map[string]string) error {
+	runningApp, exists := getApp(appName)
+	if !exists {
+		return fmt.Errorf("app does not exists, create a new application first")
+	}
+
+	if runningApp.Parameters == nil {
+		runningApp.Parameters = make(map[string]string)
+	}
+
+	for k, v := range parameters {
+		runningApp.Parameters[k] = v
+	}
+
+	// clearing the current state, so that new parameters are applied
+	runningApp.LiveState = ""
+
+	runningApp.UpdatedAt = time.Now()
+
+	runningApp.SyncTrigger <- application.UpdateSync
+
+	return nil
+}
+
 func Details(appName string) (application.Application, error) {
 	runningApp, exists := getApp(appName)
 	if !exists {
@@ -119,6 +179,38 @@ func List() AppList {
 	return res
 }
 
+// RemoveCluster removes the cluster when no application is deployed on it,
+// otherwise every sync of those applications would fail.
+func RemoveCluster(name string) error {
+	for _, app := range Applications {
+		if app.Destination == name {
+			return fmt.Errorf("cluster %s is the destination of application %s, update or remove the application first", name, app.Name)
+		}
+	}
+
+	return cluster.Remove(name)
+}
+
+func checkTarget(target string) error {
+	if target == "" || target == application.TargetSwarm || target == application.TargetDocker {
+		return nil
+	}
+
+	return fmt.Errorf("invalid target: %s, must be %s or %s", target, application.TargetSwarm, application.TargetDocker)
+}
+
+func checkDestination(destination string) error {
+	if destination == "" || destination == cluster.LocalCluster {
+		return nil
+	}
+
+	if _, found := cluster.FindCluster(destination); !found {
+		return fmt.Errorf("destination cluster does not exists: %s", destination)
+	}
+
+	return nil
+}
+
 func getApp(name string) (*application.Application, bool) {
 	for _, app := range Applications {
 		if app.Name == name {
@@ -170,19 +262,28 @@ func RemoveApplication(appName string) error {
 	slog.Info("Removing application", "app name", appName)
 	go makeAppStatusProcessing(appName)
 
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+	app, exists := getApp(appName)
+	if !exists {
+		return fmt.Errorf("app does not exists, create a new application first")
+	}
+
+	cli, err := cluster.NewClient(app.Destination)
 	if err != nil {
 		return err
 	}
+	defer cli.Close()
+
+	if app.Target == application.TargetDocker {
+		if err := removeContainers(cli, appName); err != nil {
+			return err
+		}
+	}
 
 	runningService, err := cli.ServiceList(context.Background(), types.ServiceListOptions{})
-	if err != nil {
+	if err != nil && app.Target != application.TargetDocker {
 		return err
 	}
 
-	// set (unique) of network to remove
-	networksToRemove := map[string]bool{}
-
 	for _, svc := range runningService {
 		name := svc.Spec.Labels["com.docker.stack.namespace"]
 
@@ -190,17 +291,24 @@ func RemoveApplication(appName string) error {
 			if err := cli.ServiceRemove(context.Background(), svc.ID); err != nil {
 				return err
 			}
-
-			for _, nets := range svc.Spec.TaskTemplate.Networks {
-				// nets.Target is the network ID
-				networksToRemove[nets.Target] = true
-			}
 		}
 	}
 
+	if app.Target != application.TargetDocker {
+		application.RemoveObjects(cli, appName)
+	}
+
+	// only the networks created by meltcd are removed, not the external networks
+	networksToRemove, err := cli.NetworkList(context.Background(), types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.stack.namespace="+appName)),
+	})
+	if err != nil {
+		return err
+	}
+
 	var wg sync.WaitGroup
 
-	for networkID := range networksToRemove {
+	for _, net := range networksToRemove {
 		wg.Add(1)
 
 		go func(nid string) {
@@ -218,7 +326,7 @@ func RemoveApplication(appName string) error {
 					break
 				}
 			}
-		}(networkID)
+		}(net.ID)
 	}
 
 	wg.Wait()
@@ -227,6 +335,25 @@ func RemoveApplication(appName string) error {
 	return nil
 }
 
+// removeContainers removes the containers of application deployed on standalone docker
+func removeContainers(cli *client.Client, appName string) error {
+	containers, err := cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.stack.namespace="+appName)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		if err := cli.ContainerRemove(context.Background(), c.ID, container.RemoveOptions{Force: true}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func removeSvcFromApps(appName string) {
 	tmp := make([]*application.Application, 0)
 
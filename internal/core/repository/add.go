@@ -29,19 +29,22 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 type Repository struct {
 	URL, ImageRef, Secret string
-	Reachable             bool
+	// ssh private key (deploy key) for git repositories
+	SSHKey, SSHPassphrase, KnownHosts string
+	InsecureIgnoreHostKey             bool
+	Reachable                         bool
 }
 
 var repositories []*Repository
 
-func (r *Repository) saveCredential(username, password string) {
-	r.Secret = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+func (r *Repository) saveCredential(cred Credential) {
+	r.Secret = base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))
+	r.saveSSHKey(cred)
 }
 
 func (r *Repository) GetRegistryAuth() (string, error) {
@@ -79,22 +82,27 @@ func (r *Repository) getCredential() (username, password string) {
 	return username, password
 }
 
-func (r *Repository) checkReachability(username, password string) {
+func (r *Repository) checkReachability() {
 	if r.URL != "" {
 		fs := memfs.New()
 		storage := memory.NewStorage()
 
-		_, err := git.Clone(storage, fs, &git.CloneOptions{
+		auth, err := r.getGitAuth()
+		if err != nil {
+			slog.Error(err.Error())
+			r.Reachable = false
+			return
+		}
+
+		_, err = git.Clone(storage, fs, &git.CloneOptions{
 			URL:          r.URL,
 			SingleBranch: true,
 			Depth:        1,
-			Auth: &http.BasicAuth{
-				Username: username,
-				Password: password,
-			},
+			Auth:         auth,
 		})
 
 		if err != nil {
+			slog.Error(err.Error())
 			r.Reachable = false
 		}
 	} else if r.ImageRef != "" {
@@ -129,7 +137,7 @@ func (r *Repository) checkReachability(username, password string) {
 }
 
 // url is git url or container image name
-func Add(url, imageRef, username, password string) error {
+func Add(url, imageRef string, cred Credential) error {
 	// since eight url is there of imageRef,
 	name := url + imageRef
 	repo, found := FindRepo(name)
@@ -139,10 +147,10 @@ func Add(url, imageRef, username, password string) error {
 
 	repo.URL = url
 	repo.ImageRef = imageRef
-	repo.saveCredential(username, password)
+	repo.saveCredential(cred)
 	repo.Reachable = true
 
-	go repo.checkReachability(username, password)
+	go repo.checkReachability()
 
 	repositories = append(repositories, repo)
 	return nil
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/base64"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Credential of a private repository, git repositories can use
+// basic auth (username and password) or ssh private key (deploy keys)
+type Credential struct {
+	Username, Password string
+	// PEM encoded ssh private key and its passphrase
+	SSHKey, SSHPassphrase string
+	// Content of known_hosts file used to verify the git host,
+	// when empty the default known_hosts files (or $SSH_KNOWN_HOSTS) are used
+	KnownHosts            string
+	InsecureIgnoreHostKey bool
+}
+
+func (r *Repository) saveSSHKey(cred Credential) {
+	r.SSHKey = base64.StdEncoding.EncodeToString([]byte(cred.SSHKey))
+	r.SSHPassphrase = base64.StdEncoding.EncodeToString([]byte(cred.SSHPassphrase))
+	r.KnownHosts = base64.StdEncoding.EncodeToString([]byte(cred.KnownHosts))
+	r.InsecureIgnoreHostKey = cred.InsecureIgnoreHostKey
+}
+
+func (r *Repository) getSSHKey() (key, passphrase, knownHosts string, err error) {
+	d, err := base64.StdEncoding.DecodeString(r.SSHKey)
+	if err != nil {
+		return "", "", "", err
+	}
+	key = string(d)
+
+	d, err = base64.StdEncoding.DecodeString(r.SSHPassphrase)
+	if err != nil {
+		return "", "", "", err
+	}
+	passphrase = string(d)
+
+	d, err = base64.StdEncoding.DecodeString(r.KnownHosts)
+	if err != nil {
+		return "", "", "", err
+	}
+	knownHosts = string(d)
+
+	return key, passphrase, knownHosts, nil
+}
+
+// getGitAuth returns the auth method for git operations on the repository
+// ssh key is used if present otherwise basic auth.
+func (r *Repository) getGitAuth() (transport.AuthMethod, error) {
+	if r.SSHKey == "" {
+		username, password := r.getCredential()
+		if username == "" && password == "" {
+			return nil, nil
+		}
+
+		return &http.BasicAuth{
+			Username: username,
+			Password: password,
+		}, nil
+	}
+
+	key, passphrase, knownHosts, err := r.getSSHKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// user is part of the url like git@github.com:org/repo.git
+	user := "git"
+	if ep, err := transport.NewEndpoint(r.URL); err == nil && ep.User != "" {
+		user = ep.User
+	}
+
+	auth, err := ssh.NewPublicKeys(user, []byte(key), passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.InsecureIgnoreHostKey {
+		auth.HostKeyCallback = gossh.InsecureIgnoreHostKey() //nolint:gosec
+	} else if knownHosts != "" {
+		auth.HostKeyCallback, err = knownHostsCallback(knownHosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return auth, nil
+}
+
+// knownHostsCallback creates host key callback from the content
+// of known_hosts file, since knownhosts can only read from files.
+func knownHostsCallback(knownHosts string) (gossh.HostKeyCallback, error) {
+	f, err := os.CreateTemp("", "meltcd_known_hosts-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.WriteString(knownHosts); err != nil {
+		return nil, err
+	}
+
+	return ssh.NewKnownHostsCallback(f.Name())
+}
+
+// GetGitAuth finds the auth method for git repository url,
+// nil is returned for public repositories
+func GetGitAuth(repoURL string) (transport.AuthMethod, error) {
+	repo, found := FindRepo(repoURL)
+	if !found {
+		return nil, nil
+	}
+
+	return repo.getGitAuth()
+}
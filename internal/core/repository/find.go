@@ -17,38 +17,29 @@ limitations under the License.
 package repository
 
 import (
-	"log/slog"
 	"strings"
 )
 
-func FindCreds(repoName string) (string, string) {
-	repo, found := FindRepo(repoName)
-	if !found {
-		return "", ""
-	}
-
-	username, password := repo.getCredential()
+func FindRepo(name string) (*Repository, bool) {
+	image := trimTag(name)
 
-	if username == "" || password == "" {
-		slog.Error("username and password not found in secret")
-		return "", ""
+	for _, x := range repositories {
+		if x.URL == name || x.URL+".git" == name || x.URL == name+".git" || x.ImageRef == name || x.ImageRef == image {
+			return x, true
+		}
 	}
 
-	return username, password
+	return &Repository{}, false
 }
 
-func FindRepo(name string) (*Repository, bool) {
-	for _, x := range repositories {
-		var image string
-		imageWithOutTags := strings.Split(name, ":")
-		if len(imageWithOutTags) >= 1 {
-			image = imageWithOutTags[0]
-		}
+// trimTag removes the tag and digest of image, the port of registry
+// like "host:5000/app:tag" is kept since it is before the last "/"
+func trimTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
 
-		if x.URL == name || x.URL+".git" == name || x.URL == name+".git" || x.ImageRef == image {
-			return x, true
-		}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
 	}
 
-	return &Repository{}, false
+	return image
 }
@@ -0,0 +1,47 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import "testing"
+
+func TestFindRepoImage(t *testing.T) {
+	repositories = []*Repository{
+		{ImageRef: "ghcr.io/org/app"},
+		{ImageRef: "registry.local:5000"},
+		{ImageRef: "registry.local:5000/team/api"},
+	}
+	defer func() { repositories = nil }()
+
+	tests := map[string]string{
+		"ghcr.io/org/app:1.0":                       "ghcr.io/org/app",
+		"ghcr.io/org/app@sha256:abc":                "ghcr.io/org/app",
+		"registry.local:5000":                       "registry.local:5000",
+		"registry.local:5000/team/api:0a1b2c3":      "registry.local:5000/team/api",
+		"registry.local:5000/team/api:0a1b2c3@sha2": "registry.local:5000/team/api",
+	}
+
+	for name, expected := range tests {
+		repo, found := FindRepo(name)
+		if !found || repo.ImageRef != expected {
+			t.Errorf("%s: expected %s, got %v", name, expected, repo.ImageRef)
+		}
+	}
+
+	if _, found := FindRepo("registry.local:5000/team/web:1.0"); found {
+		t.Error("image of other repository is found")
+	}
+}
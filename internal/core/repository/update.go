@@ -20,7 +20,7 @@ import (
 	"errors"
 )
 
-func Update(url, image, username, password string) error {
+func Update(url, image string, cred Credential) error {
 	// eight url is empty or image is empty
 	// so combining them will give the name
 	repo, found := FindRepo(url + image)
@@ -28,6 +28,6 @@ func Update(url, image, username, password string) error {
 		return errors.New("repository does not exists")
 	}
 
-	repo.saveCredential(username, password)
+	repo.saveCredential(cred)
 	return nil
 }
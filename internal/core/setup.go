@@ -24,12 +24,14 @@ import (
 	"log/slog"
 
 	"github.com/kunalsin9h/meltcd/internal/core/auth"
+	"github.com/kunalsin9h/meltcd/internal/core/cluster"
 	"github.com/kunalsin9h/meltcd/internal/core/repository"
 )
 
 const MELTCD_DIR = ".meltcd"                         //nolint
 const MELTCD_APPLICATIONS_FILE = "applications.json" //nolint
 const MELTCD_REPOSITORY_FILE = "repositories.json"   //nolint
+const MELTCD_CLUSTER_FILE = "clusters.json"          //nolint
 const MELTCD_AUTH_FILE = "auth.json"                 //nolint
 const MELTCD_ACCESS_TOKEN = "access_token.txt"       //nolint
 const MELTCD_LOG_FILE = "general.log"                //nolint
@@ -49,6 +51,7 @@ func Setup() error {
 func meltcdState() error {
 	applicationsFile := getAppFile()
 	repositoryFile := getRepositoryFile()
+	clusterFile := getClusterFile()
 	authFile := getAuthFile()
 	accessTokenFile := getAccessTokenFile()
 
@@ -67,7 +70,7 @@ func meltcdState() error {
 		}
 	}
 
-	for _, f := range []string{applicationsFile, repositoryFile, accessTokenFile} {
+	for _, f := range []string{applicationsFile, repositoryFile, clusterFile, accessTokenFile} {
 		_, err = os.Stat(f)
 		if err != nil {
 			slog.Info(fmt.Sprintf("Creating file: %s\n", f))
@@ -78,6 +81,24 @@ func meltcdState() error {
 		}
 	}
 
+	// the files were created with default permissions before, they have private keys
+	for _, f := range []string{clusterFile, repositoryFile} {
+		if err := os.Chmod(f, 0600); err != nil {
+			return err
+		}
+	}
+
+	// clusters are loaded before applications, since
+	// applications start syncing on their destination as soon as they are loaded
+	clusterData, err := os.ReadFile(clusterFile)
+	if err != nil {
+		return err
+	}
+
+	if err := cluster.LoadData(&clusterData); err != nil {
+		slog.Warn("Cluster state file is empty", "error", err.Error())
+	}
+
 	appData, err := os.ReadFile(applicationsFile)
 	if err != nil {
 		return err
@@ -127,7 +148,28 @@ func ShutDown() error {
 		return err
 	}
 
-	if err := os.WriteFile(repoFile, repoData, os.ModePerm); err != nil {
+	// repositories have the passwords, ssh private keys and their passphrases
+	if err := os.WriteFile(repoFile, repoData, 0600); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(repoFile, 0600); err != nil {
+		return err
+	}
+
+	clusterFile := getClusterFile()
+
+	clusterData, err := cluster.GetData()
+	if err != nil {
+		return err
+	}
+
+	// clusters have the TLS and ssh private keys, only the owner can read them
+	if err := os.WriteFile(clusterFile, clusterData, 0600); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(clusterFile, 0600); err != nil {
 		return err
 	}
 
@@ -188,6 +230,11 @@ func getRepositoryFile() string {
 	return path.Join(meltcdDir, MELTCD_REPOSITORY_FILE)
 }
 
+func getClusterFile() string {
+	meltcdDir := getMeltcdDir()
+	return path.Join(meltcdDir, MELTCD_CLUSTER_FILE)
+}
+
 func getAuthFile() string {
 	meltcdDir := getMeltcdDir()
 	return path.Join(meltcdDir, MELTCD_AUTH_FILE)
@@ -0,0 +1,60 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kunalsin9h/meltcd/internal/core"
+)
+
+type ParametersBody struct {
+	Parameters map[string]string `json:"parameters"`
+}
+
+// SetParameters godoc
+//
+//	@summary	Set parameters of application for its service file templates
+//	@tags		Apps
+//	@Security	ApiKeyAuth || cookies
+//	@accept		json
+//	@produce	json
+//	@param		app_name	path	string			true	"Application name"
+//	@param		request		body	ParametersBody	true	"Parameters to set"
+//	@success	202
+//	@failure	400	{object}	GlobalResponse
+//	@failure	500	{object}	GlobalResponse
+//	@router		/apps/{app_name}/parameters [patch]
+func SetParameters(c *fiber.Ctx) error {
+	appName := c.Params("app_name")
+
+	var body ParametersBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(GlobalResponse{
+			Message: "Failed to parse request body",
+		})
+	}
+
+	if err := core.SetParameters(appName, body.Parameters); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(GlobalResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.SendStatus(http.StatusAccepted)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kunalsin9h/meltcd/internal/core"
+	"github.com/kunalsin9h/meltcd/internal/core/cluster"
+	"github.com/kunalsin9h/meltcd/server/api/app"
+)
+
+type ClusterDetails struct {
+	Name          string `json:"name"`
+	Host          string `json:"host"`
+	TLSCACert     string `json:"tls_ca_cert"`
+	TLSCert       string `json:"tls_cert"`
+	TLSKey        string `json:"tls_key"`
+	TLSSkipVerify bool   `json:"tls_skip_verify"`
+	SSHKey        string `json:"ssh_key"`
+	SSHPassphrase string `json:"ssh_passphrase"`
+	SSHInsecure   bool   `json:"ssh_insecure"`
+}
+
+// Add godoc
+//
+//	@summary	Add a new cluster
+//	@tags		Cluster
+//	@Security	ApiKeyAuth || cookies
+//	@accept		json
+//	@produce	json
+//	@param		request	body		ClusterDetails	true	"Cluster details"
+//	@success	202		{object}	app.GlobalResponse
+//	@failure	400		{object}	app.GlobalResponse
+//	@router		/clusters [post]
+func Add(c *fiber.Ctx) error {
+	var payload ClusterDetails
+
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(app.GlobalResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if payload.Name == "" || payload.Host == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(app.GlobalResponse{
+			Message: "missing name or host in request body",
+		})
+	}
+
+	if err := cluster.Add(payload.Name, payload.Host, cluster.Credentials{
+		TLSCACert:     payload.TLSCACert,
+		TLSCert:       payload.TLSCert,
+		TLSKey:        payload.TLSKey,
+		TLSSkipVerify: payload.TLSSkipVerify,
+		SSHKey:        payload.SSHKey,
+		SSHPassphrase: payload.SSHPassphrase,
+		SSHInsecure:   payload.SSHInsecure,
+	}); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(app.GlobalResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(app.GlobalResponse{
+		Message: "Added Cluster",
+	})
+}
+
+type ListData struct {
+	Data []cluster.ClusterData `json:"data"`
+}
+
+// List godoc
+//
+//	@summary	Get a list all clusters
+//	@Security	ApiKeyAuth || cookies
+//	@tags		Cluster
+//	@produce	json
+//	@success	200	{object}	ListData
+//	@router		/clusters [get]
+func List(c *fiber.Ctx) error {
+	list := cluster.List()
+
+	return c.Status(fiber.StatusOK).JSON(ListData{
+		Data: list,
+	})
+}
+
+// Remove godoc
+//
+//	@summary	Remove a cluster
+//	@Security	ApiKeyAuth || cookies
+//	@tags		Cluster
+//	@produce	json
+//	@param		name	path		string	true	"Cluster name"
+//	@success	200		{object}	app.GlobalResponse
+//	@failure	400		{object}	app.GlobalResponse
+//	@router		/clusters/{name} [delete]
+func Remove(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	if err := core.RemoveCluster(name); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(app.GlobalResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(app.GlobalResponse{
+		Message: "removed cluster",
+	})
+}
@@ -25,10 +25,34 @@ import (
 )
 
 type PrivateRepoDetails struct {
-	URL      string `json:"url"`
-	ImageRef string `json:"image_ref"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	URL                   string `json:"url"`
+	ImageRef              string `json:"image_ref"`
+	Username              string `json:"username"`
+	Password              string `json:"password"`
+	SSHKey                string `json:"ssh_key"`
+	SSHPassphrase         string `json:"ssh_passphrase"`
+	KnownHosts            string `json:"known_hosts"`
+	InsecureIgnoreHostKey bool   `json:"insecure_ignore_host_key"`
+}
+
+func (p *PrivateRepoDetails) hasCredential() bool {
+	// ssh key is only for git repositories
+	if p.SSHKey != "" {
+		return p.URL != ""
+	}
+
+	return p.Username != "" && p.Password != ""
+}
+
+func (p *PrivateRepoDetails) credential() repository.Credential {
+	return repository.Credential{
+		Username:              p.Username,
+		Password:              p.Password,
+		SSHKey:                p.SSHKey,
+		SSHPassphrase:         p.SSHPassphrase,
+		KnownHosts:            p.KnownHosts,
+		InsecureIgnoreHostKey: p.InsecureIgnoreHostKey,
+	}
 }
 
 // Add godoc
@@ -52,15 +76,15 @@ func Add(c *fiber.Ctx) error { // nolint:all
 		})
 	}
 
-	if (payload.URL == "" && payload.ImageRef == "") || payload.Username == "" || payload.Password == "" {
+	if (payload.URL == "" && payload.ImageRef == "") || !payload.hasCredential() {
 		return c.Status(fiber.StatusBadRequest).JSON(app.GlobalResponse{
-			Message: "missing url or imageRef, username or password in request body",
+			Message: "missing url or imageRef, username and password or ssh key in request body",
 		})
 	}
 
 	url, _ := strings.CutSuffix(payload.URL, "/")
 
-	if err := repository.Add(url, payload.ImageRef, payload.Username, payload.Password); err != nil {
+	if err := repository.Add(url, payload.ImageRef, payload.credential()); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(app.GlobalResponse{
 			Message: err.Error(),
 		})
@@ -154,13 +178,13 @@ func Update(c *fiber.Ctx) error { // nolint:all
 		})
 	}
 
-	if (payload.URL == "" && payload.ImageRef == "") || payload.Username == "" || payload.Password == "" {
+	if (payload.URL == "" && payload.ImageRef == "") || !payload.hasCredential() {
 		return c.Status(fiber.StatusBadRequest).JSON(app.GlobalResponse{
-			Message: "missing url, username or password in request body",
+			Message: "missing url, username and password or ssh key in request body",
 		})
 	}
 
-	if err := repository.Update(payload.URL, payload.ImageRef, payload.Username, payload.Password); err != nil {
+	if err := repository.Update(payload.URL, payload.ImageRef, payload.credential()); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(app.GlobalResponse{
 			Message: err.Error(),
 		})
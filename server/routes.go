@@ -32,6 +32,7 @@ import (
 	"github.com/kunalsin9h/meltcd/internal/core"
 	Api "github.com/kunalsin9h/meltcd/server/api"
 	appApi "github.com/kunalsin9h/meltcd/server/api/app"
+	clusterApi "github.com/kunalsin9h/meltcd/server/api/cluster"
 	repoApi "github.com/kunalsin9h/meltcd/server/api/repo"
 	"github.com/kunalsin9h/meltcd/server/middleware"
 	"github.com/kunalsin9h/meltcd/version"
@@ -197,6 +198,7 @@ func Serve(ln net.Listener, origins string, verboseOutput bool) error {
 	apps.Delete("/:app_name", appApi.Remove)
 	apps.Put("/", appApi.Update)
 	apps.Post("/:app_name/refresh", appApi.Refresh)
+	apps.Patch("/:app_name/parameters", appApi.SetParameters)
 	apps.Post("/:app_name/recreate", appApi.Recreate)
 
 	repo := api.Group("repo", middleware.VerifyUser)
@@ -205,6 +207,11 @@ func Serve(ln net.Listener, origins string, verboseOutput bool) error {
 	repo.Delete("/", repoApi.Remove)
 	repo.Put("/", repoApi.Update)
 
+	clusters := api.Group("clusters", middleware.VerifyUser)
+	clusters.Get("/", clusterApi.List)
+	clusters.Post("/", clusterApi.Add)
+	clusters.Delete("/:name", clusterApi.Remove)
+
 	info := fmt.Sprintf("Listening on %s (version: %s)\n", ln.Addr(), version.Version)
 
 	slog.Info(info)
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+// Build is the build of service image from the repository, in short syntax
+// with the context directory like "./web" or long syntax with dockerfile, args and target.
+type Build struct {
+	Context    string            `yaml:"context"` // relative to the service file, "." by default
+	Dockerfile string            `yaml:"dockerfile"`
+	Args       MappingWithEquals `yaml:"args"`
+	Target     string            `yaml:"target"`
+	Labels     Mapping           `yaml:"labels"`
+}
+
+func (b *Build) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		*b = Build{Context: short}
+		return nil
+	}
+
+	type long Build
+	var l long
+	if err := unmarshal(&l); err != nil {
+		return err
+	}
+
+	*b = Build(l)
+	return nil
+}
+
+// GetBuildArgs returns the build args, args without value are taken from Lookup
+// and left unset when Lookup does not have them.
+func (d *DockerSwarm) GetBuildArgs(build *Build) map[string]*string {
+	args := make(map[string]*string, len(build.Args))
+
+	for k, v := range build.Args {
+		if v == nil && d.Lookup != nil {
+			if value, found := d.Lookup(k); found {
+				v = &value
+			}
+		}
+		args[k] = v
+	}
+
+	return args
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestBuild(t *testing.T) {
+	var s Service
+	if err := yaml.Unmarshal([]byte("build: ./web"), &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.Build == nil || s.Build.Context != "./web" {
+		t.Error("short build is not parsed as context", s.Build)
+	}
+
+	content := `
+build:
+  context: ./api
+  dockerfile: Dockerfile.prod
+  target: release
+  args:
+    - VERSION=1.0
+    - TOKEN
+    - MISSING
+`
+	s = Service{}
+	if err := yaml.Unmarshal([]byte(content), &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.Build.Context != "./api" || s.Build.Dockerfile != "Dockerfile.prod" || s.Build.Target != "release" {
+		t.Error("long build is not parsed", s.Build)
+	}
+
+	d := DockerSwarm{
+		Lookup: func(key string) (string, bool) {
+			if key == "TOKEN" {
+				return "secret", true
+			}
+			return "", false
+		},
+	}
+
+	args := d.GetBuildArgs(s.Build)
+	if len(args) != 3 {
+		t.Fatal("wrong build args", args)
+	}
+
+	if args["VERSION"] == nil || *args["VERSION"] != "1.0" {
+		t.Error("build arg with value is not kept", args)
+	}
+
+	if args["TOKEN"] == nil || *args["TOKEN"] != "secret" {
+		t.Error("build arg without value is not taken from lookup", args)
+	}
+
+	if args["MISSING"] != nil {
+		t.Error("build arg not in lookup must be unset", args)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anmitsu/go-shlex"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-units"
+)
+
+// ShellCommand is the command or entrypoint of service, either as string
+// like "npm run start" which is split like a shell does, or list of arguments.
+type ShellCommand []string
+
+func (c *ShellCommand) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		args, err := shlex.Split(short, true)
+		if err != nil {
+			return fmt.Errorf("invalid command %q: %w", short, err)
+		}
+
+		*c = args
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	*c = list
+	return nil
+}
+
+// setContainerOptions sets the container level options of service.
+// Entrypoint is the swarm Command and command are its Args.
+func (s *Service) setContainerOptions(cs *swarm.ContainerSpec) error {
+	ulimits, err := getUlimits(s.Ulimits)
+	if err != nil {
+		return err
+	}
+
+	cs.Ulimits = ulimits
+	cs.Sysctls = s.Sysctls
+	cs.CapabilityAdd = s.CapAdd
+	cs.CapabilityDrop = s.CapDrop
+	cs.Hosts = getHosts(s.ExtraHosts)
+
+	cs.Command = s.Entrypoint
+	cs.Args = s.Command
+	cs.Dir = s.WorkingDir
+	cs.User = s.User
+	cs.Hostname = s.Hostname
+	cs.StopSignal = s.StopSignal
+	cs.Init = s.Init
+	cs.TTY = s.Tty
+	cs.OpenStdin = s.StdinOpen
+	cs.ReadOnly = s.ReadOnly
+
+	if s.StopGracePeriod != "" {
+		d, err := parseDuration("stop_grace_period", s.StopGracePeriod)
+		if err != nil {
+			return err
+		}
+		cs.StopGracePeriod = &d
+	}
+
+	return nil
+}
+
+// Logging is the log driver of service, like json-file with options max-size and max-file
+type Logging struct {
+	Driver  string            `yaml:"driver"`
+	Options map[string]string `yaml:"options"`
+}
+
+// Ulimit is like "nofile: 65535" for same soft and hard limit, or with soft and hard
+type Ulimit struct {
+	Soft int64 `yaml:"soft"`
+	Hard int64 `yaml:"hard"`
+}
+
+func (u *Ulimit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single int64
+	if err := unmarshal(&single); err == nil {
+		*u = Ulimit{Soft: single, Hard: single}
+		return nil
+	}
+
+	type long Ulimit
+	var l long
+	if err := unmarshal(&l); err != nil {
+		return err
+	}
+
+	*u = Ulimit(l)
+	return nil
+}
+
+// getLogDriver returns nil when logging is not specified, so the default driver of docker is used
+func (l *Logging) getLogDriver() *swarm.Driver {
+	if l == nil || (l.Driver == "" && len(l.Options) == 0) {
+		return nil
+	}
+
+	return &swarm.Driver{
+		Name:    l.Driver,
+		Options: l.Options,
+	}
+}
+
+// getUlimits returns the ulimits sorted by name
+func getUlimits(ulimits map[string]Ulimit) ([]*units.Ulimit, error) {
+	names := make([]string, 0, len(ulimits))
+	for name := range ulimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []*units.Ulimit
+	for _, name := range names {
+		u := ulimits[name]
+		if u.Soft > u.Hard {
+			return nil, fmt.Errorf("ulimit %s: soft limit %d is more than hard limit %d", name, u.Soft, u.Hard)
+		}
+
+		result = append(result, &units.Ulimit{Name: name, Soft: u.Soft, Hard: u.Hard})
+	}
+
+	return result, nil
+}
+
+// getHosts converts the extra hosts like "host:ip" to swarm format "ip host"
+func getHosts(extraHosts ExtraHosts) []string {
+	var hosts []string
+	for _, h := range extraHosts {
+		host, ip, _ := strings.Cut(h, ":")
+		hosts = append(hosts, ip+" "+host)
+	}
+
+	return hosts
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestContainerOptions(t *testing.T) {
+	var d DockerSwarm
+	err := yaml.Unmarshal([]byte(`
+services:
+  web:
+    image: node
+    command: npm run "start app"
+    entrypoint: ["/bin/sh", "-c"]
+    working_dir: /app
+    user: "1000:1000"
+    hostname: web
+    stop_grace_period: 1m30s
+    stop_signal: SIGINT
+    init: true
+    tty: true
+    read_only: true
+`), &d)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cs := services[0].TaskTemplate.ContainerSpec
+
+	if !reflect.DeepEqual(cs.Args, []string{"npm", "run", "start app"}) {
+		t.Errorf("command not split like shell: %q", cs.Args)
+	}
+
+	if !reflect.DeepEqual(cs.Command, []string{"/bin/sh", "-c"}) {
+		t.Errorf("entrypoint is not used as command: %q", cs.Command)
+	}
+
+	if cs.Dir != "/app" || cs.User != "1000:1000" || cs.Hostname != "web" || cs.StopSignal != "SIGINT" {
+		t.Errorf("unexpected container options: %+v", cs)
+	}
+
+	if cs.StopGracePeriod == nil || *cs.StopGracePeriod != 90*time.Second {
+		t.Errorf("unexpected stop_grace_period: %v", cs.StopGracePeriod)
+	}
+
+	if cs.Init == nil || !*cs.Init || !cs.TTY || !cs.ReadOnly {
+		t.Errorf("init, tty and read_only must be set: %+v", cs)
+	}
+
+	containers, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "net"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if containers[0].Config.StopTimeout == nil || *containers[0].Config.StopTimeout != 90 {
+		t.Errorf("stop_grace_period not used as stop timeout")
+	}
+
+	if !reflect.DeepEqual([]string(containers[0].Config.Entrypoint), []string{"/bin/sh", "-c"}) {
+		t.Errorf("unexpected standalone entrypoint: %q", containers[0].Config.Entrypoint)
+	}
+}
+
+func TestInvalidStopGracePeriod(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{"web": {Image: "nginx", StopGracePeriod: "soon"}},
+	}
+
+	if _, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{}); err == nil {
+		t.Error("expected error for invalid stop_grace_period")
+	}
+}
+
+func TestLoggingUlimitsAndHosts(t *testing.T) {
+	var d DockerSwarm
+	err := yaml.Unmarshal([]byte(`
+services:
+  web:
+    image: nginx
+    logging:
+      driver: json-file
+      options:
+        max-size: 10m
+        max-file: 3
+    ulimits:
+      nproc: 65535
+      nofile:
+        soft: 20000
+        hard: 40000
+    sysctls:
+      - net.core.somaxconn=1024
+    cap_add:
+      - NET_ADMIN
+    cap_drop:
+      - ALL
+    extra_hosts:
+      - somehost:162.242.195.82
+      - myhostv6:::1
+`), &d)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	logDriver := services[0].TaskTemplate.LogDriver
+	if logDriver == nil || logDriver.Name != "json-file" || logDriver.Options["max-size"] != "10m" || logDriver.Options["max-file"] != "3" {
+		t.Errorf("unexpected log driver: %+v", logDriver)
+	}
+
+	cs := services[0].TaskTemplate.ContainerSpec
+
+	if len(cs.Ulimits) != 2 || cs.Ulimits[0].Name != "nofile" || cs.Ulimits[0].Soft != 20000 || cs.Ulimits[0].Hard != 40000 ||
+		cs.Ulimits[1].Name != "nproc" || cs.Ulimits[1].Soft != 65535 || cs.Ulimits[1].Hard != 65535 {
+		t.Errorf("unexpected ulimits: %v", cs.Ulimits)
+	}
+
+	if cs.Sysctls["net.core.somaxconn"] != "1024" {
+		t.Errorf("unexpected sysctls: %v", cs.Sysctls)
+	}
+
+	if !reflect.DeepEqual(cs.CapabilityAdd, []string{"NET_ADMIN"}) || !reflect.DeepEqual(cs.CapabilityDrop, []string{"ALL"}) {
+		t.Errorf("unexpected capabilities: %v %v", cs.CapabilityAdd, cs.CapabilityDrop)
+	}
+
+	if !reflect.DeepEqual(cs.Hosts, []string{"162.242.195.82 somehost", "::1 myhostv6"}) {
+		t.Errorf("unexpected hosts: %v", cs.Hosts)
+	}
+
+	containers, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "net"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	hostConfig := containers[0].HostConfig
+	if hostConfig.LogConfig.Type != "json-file" || len(hostConfig.Ulimits) != 2 {
+		t.Errorf("unexpected standalone host config: %+v", hostConfig)
+	}
+
+	if !reflect.DeepEqual(hostConfig.ExtraHosts, []string{"somehost:162.242.195.82", "myhostv6:::1"}) {
+		t.Errorf("unexpected standalone extra hosts: %v", hostConfig.ExtraHosts)
+	}
+}
+
+func TestInvalidUlimit(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {Image: "nginx", Ulimits: map[string]Ulimit{"nofile": {Soft: 2, Hard: 1}}},
+		},
+	}
+
+	if _, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{}); err == nil {
+		t.Error("expected error for soft limit more than hard limit")
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-connections/nat"
+)
+
+// ContainerSpec is a service deployed as plain docker container,
+// for docker engines which are not running in swarm mode.
+type ContainerSpec struct {
+	Name             string
+	ServiceName      string
+	Replicas         uint64
+	Config           *container.Config
+	HostConfig       *container.HostConfig
+	NetworkingConfig *network.NetworkingConfig
+	// Networks are the ids of the networks in the order of service networks,
+	// the container is created on the first one and connected to the others
+	Networks []string
+}
+
+// GetContainerSpec makes the container specs for standalone docker from the same
+// service specs used for swarm, so that both the targets get the same translation.
+func (d *DockerSwarm) GetContainerSpec(appName string, networkIDs map[string]string) ([]ContainerSpec, error) {
+	for serviceName, svc := range d.Services {
+		if !d.isEnabled(svc) {
+			continue
+		}
+
+		if len(svc.Secrets) != 0 || len(svc.Configs) != 0 {
+			return []ContainerSpec{}, fmt.Errorf("service %s: secrets and configs are only supported on swarm", serviceName)
+		}
+
+		if svc.Deploy.isJob() {
+			return []ContainerSpec{}, fmt.Errorf("service %s: %s mode is only supported on swarm", serviceName, svc.Deploy.Mode)
+		}
+	}
+
+	services, err := d.GetServiceSpec(appName, networkIDs, Objects{})
+	if err != nil {
+		return []ContainerSpec{}, err
+	}
+
+	specs := make([]ContainerSpec, 0, len(services))
+
+	for _, service := range services {
+		serviceName, _ := strings.CutPrefix(service.Name, appName+"_")
+
+		containerSpec, err := toContainerSpec(service, d.Services[serviceName])
+		if err != nil {
+			return []ContainerSpec{}, err
+		}
+
+		containerSpec.ServiceName = serviceName
+
+		// every replica is a container on the same host, they can not publish the same host port
+		if containerSpec.Replicas > 1 {
+			for port, bindings := range containerSpec.HostConfig.PortBindings {
+				for _, b := range bindings {
+					if b.HostPort != "" {
+						return []ContainerSpec{}, fmt.Errorf("service %s: %d replicas can not publish the host port %s (of %s) on standalone docker, use one replica or do not publish the port", serviceName, containerSpec.Replicas, b.HostPort, port)
+					}
+				}
+			}
+		}
+		specs = append(specs, containerSpec)
+	}
+
+	return specs, nil
+}
+
+func toContainerSpec(service swarm.ServiceSpec, svc Service) (ContainerSpec, error) {
+	cs := service.TaskTemplate.ContainerSpec
+
+	labels := make(map[string]string)
+	for k, v := range service.Labels {
+		labels[k] = v
+	}
+	for k, v := range cs.Labels {
+		labels[k] = v
+	}
+
+	config := &container.Config{
+		Image:       cs.Image,
+		Hostname:    cs.Hostname,
+		User:        cs.User,
+		Env:         cs.Env,
+		Cmd:         cs.Args,
+		Entrypoint:  cs.Command,
+		WorkingDir:  cs.Dir,
+		Labels:      labels,
+		Tty:         cs.TTY,
+		OpenStdin:   cs.OpenStdin,
+		StopSignal:  cs.StopSignal,
+		Healthcheck: cs.Healthcheck,
+	}
+
+	if cs.StopGracePeriod != nil {
+		timeout := int(cs.StopGracePeriod.Seconds())
+		config.StopTimeout = &timeout
+	}
+
+	hostConfig := &container.HostConfig{
+		Mounts:         cs.Mounts,
+		Init:           cs.Init,
+		ReadonlyRootfs: cs.ReadOnly,
+		CapAdd:         cs.CapabilityAdd,
+		CapDrop:        cs.CapabilityDrop,
+		Sysctls:        cs.Sysctls,
+		Isolation:      cs.Isolation,
+		GroupAdd:       cs.Groups,
+	}
+
+	for _, host := range cs.Hosts {
+		// swarm format is "IP hostname [aliases...]", docker wants "hostname:IP"
+		fields := strings.Fields(host)
+		for _, name := range fields[1:] {
+			hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, name+":"+fields[0])
+		}
+	}
+
+	if cs.DNSConfig != nil {
+		hostConfig.DNS = cs.DNSConfig.Nameservers
+		hostConfig.DNSSearch = cs.DNSConfig.Search
+		hostConfig.DNSOptions = cs.DNSConfig.Options
+	}
+
+	hostConfig.Ulimits = cs.Ulimits
+
+	if resources := service.TaskTemplate.Resources; resources != nil {
+		if resources.Limits != nil {
+			hostConfig.NanoCPUs = resources.Limits.NanoCPUs
+			hostConfig.Memory = resources.Limits.MemoryBytes
+			if resources.Limits.Pids != 0 {
+				pids := resources.Limits.Pids
+				hostConfig.PidsLimit = &pids
+			}
+		}
+		if resources.Reservations != nil {
+			hostConfig.MemoryReservation = resources.Reservations.MemoryBytes
+		}
+	}
+
+	if logDriver := service.TaskTemplate.LogDriver; logDriver != nil {
+		hostConfig.LogConfig = container.LogConfig{
+			Type:   logDriver.Name,
+			Config: logDriver.Options,
+		}
+	}
+
+	restartPolicy, err := getRestartPolicy(svc.Restart, service.TaskTemplate.RestartPolicy)
+	if err != nil {
+		return ContainerSpec{}, err
+	}
+	hostConfig.RestartPolicy = restartPolicy
+
+	// ports are taken from the compose service, since swarm port config has no host ip
+	mappings, err := getPortMappings(svc.Ports)
+	if err != nil {
+		return ContainerSpec{}, fmt.Errorf("service %s: %w", service.Name, err)
+	}
+
+	if len(mappings) != 0 {
+		config.ExposedPorts = nat.PortSet{}
+		hostConfig.PortBindings = nat.PortMap{}
+
+		for _, port := range mappings {
+			containerPort, err := nat.NewPort(string(port.Protocol), strconv.Itoa(int(port.TargetPort)))
+			if err != nil {
+				return ContainerSpec{}, err
+			}
+
+			config.ExposedPorts[containerPort] = struct{}{}
+
+			if port.PublishedPort != 0 {
+				hostConfig.PortBindings[containerPort] = append(hostConfig.PortBindings[containerPort], nat.PortBinding{
+					HostIP:   port.HostIP,
+					HostPort: strconv.Itoa(int(port.PublishedPort)),
+				})
+			}
+		}
+	}
+
+	networkingConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{},
+	}
+
+	var networks []string
+	for _, net := range service.TaskTemplate.Networks {
+		networkingConfig.EndpointsConfig[net.Target] = &network.EndpointSettings{
+			Aliases: net.Aliases,
+		}
+		networks = append(networks, net.Target)
+	}
+
+	// standalone containers do not have global mode, one container is used
+	replicas := uint64(1)
+	if service.Mode.Replicated != nil && service.Mode.Replicated.Replicas != nil {
+		replicas = *service.Mode.Replicated.Replicas
+	}
+
+	return ContainerSpec{
+		Name:             service.Name,
+		Replicas:         replicas,
+		Config:           config,
+		HostConfig:       hostConfig,
+		NetworkingConfig: networkingConfig,
+		Networks:         networks,
+	}, nil
+}
+
+// getRestartPolicy uses the compose "restart" key, if not specified the
+// deploy.restart_policy is used (restart "always" is the swarm default)
+func getRestartPolicy(restart string, policy *swarm.RestartPolicy) (container.RestartPolicy, error) {
+	if restart != "" {
+		name, count, _ := strings.Cut(restart, ":")
+
+		rp := container.RestartPolicy{
+			Name: container.RestartPolicyMode(name),
+		}
+
+		if count != "" {
+			maxRetry, err := strconv.Atoi(count)
+			if err != nil {
+				return container.RestartPolicy{}, fmt.Errorf("invalid restart: %s", restart)
+			}
+			rp.MaximumRetryCount = maxRetry
+		}
+
+		if err := container.ValidateRestartPolicy(rp); err != nil {
+			return container.RestartPolicy{}, err
+		}
+
+		return rp, nil
+	}
+
+	if policy == nil {
+		return container.RestartPolicy{Name: container.RestartPolicyAlways}, nil
+	}
+
+	switch policy.Condition {
+	case swarm.RestartPolicyConditionNone:
+		return container.RestartPolicy{Name: container.RestartPolicyDisabled}, nil
+	case swarm.RestartPolicyConditionOnFailure:
+		rp := container.RestartPolicy{Name: container.RestartPolicyOnFailure}
+		if policy.MaxAttempts != nil {
+			rp.MaximumRetryCount = int(*policy.MaxAttempts)
+		}
+		return rp, nil
+	}
+
+	return container.RestartPolicy{Name: container.RestartPolicyAlways}, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func TestGetRestartPolicy(t *testing.T) {
+	rp, err := getRestartPolicy("on-failure:3", nil)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if rp.Name != container.RestartPolicyOnFailure || rp.MaximumRetryCount != 3 {
+		t.Error("failed to parse restart on-failure:3", rp)
+	}
+
+	if _, err := getRestartPolicy("sometimes", nil); err == nil {
+		t.Error("invalid restart is not rejected")
+	}
+
+	rp, err = getRestartPolicy("", &swarm.RestartPolicy{Condition: swarm.RestartPolicyConditionNone})
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if rp.Name != container.RestartPolicyDisabled {
+		t.Error("restart_policy condition none is not mapped to no", rp)
+	}
+
+	rp, _ = getRestartPolicy("", nil)
+	if rp.Name != container.RestartPolicyAlways {
+		t.Error("default restart policy must be always", rp)
+	}
+}
+
+func TestGetContainerSpec(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {
+				Image: "nginx",
+				Ports: []Port{{Target: "80", Published: "8080", HostIP: "127.0.0.1"}},
+				Deploy: Deploy{
+					Mode: "replicated",
+				},
+			},
+		},
+	}
+
+	specs, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "network_id"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(specs) != 1 {
+		t.Fatal("expected one container spec", specs)
+	}
+
+	cs := specs[0]
+	if cs.Name != "app_web" || cs.ServiceName != "web" || cs.Replicas != 1 {
+		t.Error("wrong container spec", cs)
+	}
+
+	if cs.Config.Labels["com.docker.stack.namespace"] != "app" {
+		t.Error("container is not labelled with the namespace", cs.Config.Labels)
+	}
+
+	bindings := cs.HostConfig.PortBindings["80/tcp"]
+	if len(bindings) != 1 || bindings[0].HostPort != "8080" || bindings[0].HostIP != "127.0.0.1" {
+		t.Error("port is not published", cs.HostConfig.PortBindings)
+	}
+
+	if _, ok := cs.NetworkingConfig.EndpointsConfig["network_id"]; !ok {
+		t.Error("container is not connected to the network", cs.NetworkingConfig)
+	}
+}
+
+func TestGetContainerSpecReplicasWithPorts(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {
+				Image:  "nginx",
+				Ports:  []Port{{Target: "80", Published: "8080"}},
+				Deploy: Deploy{Replicas: ptr(uint64(2))},
+			},
+		},
+	}
+
+	if _, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "network_id"}); err == nil {
+		t.Error("replicas publishing the same host port are not rejected")
+	}
+
+	// without published host port docker picks a random port for every container
+	d.Services["web"] = Service{
+		Image:  "nginx",
+		Ports:  []Port{{Target: "80"}},
+		Deploy: Deploy{Replicas: ptr(uint64(2))},
+	}
+
+	if _, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "network_id"}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestGetContainerSpecNetworkOrder(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {Image: "nginx", Networks: ServiceNetworks{"front": nil, "back": nil, "cache": nil}},
+		},
+	}
+
+	networkIDs := map[string]string{"front": "front_id", "back": "back_id", "cache": "cache_id"}
+
+	// the order must not depend on the map iteration
+	for i := 0; i < 10; i++ {
+		specs, err := d.GetContainerSpec("app", networkIDs)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if !reflect.DeepEqual(specs[0].Networks, []string{"back_id", "cache_id", "front_id"}) {
+			t.Fatal("networks are not in the order of service networks", specs[0].Networks)
+		}
+	}
+}
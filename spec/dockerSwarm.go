@@ -17,17 +17,13 @@ limitations under the License.
 package spec
 
 import (
-	"bufio"
-	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/user"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
 
-	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/swarm"
 )
 
@@ -36,45 +32,93 @@ type DockerSwarm struct {
 	Services map[string]Service `yaml:"services"`
 	Networks map[string]Network `yaml:"networks"`
 	Volumes  map[string]Volume  `yaml:"volumes"`
+	Secrets  map[string]Object  `yaml:"secrets"`
+	Configs  map[string]Object  `yaml:"configs"`
+
+	// Files reads the files referenced by service file like env_file, relative to the service file.
+	Files FileReader `yaml:"-"`
+	// AllowHostPaths allows env_file and relative bind mounts from the filesystem of meltcd host.
+	AllowHostPaths bool `yaml:"-"`
+	// Lookup gives the value of environment variables without value like "- KEY",
+	// they are not set when Lookup is nil or does not have them.
+	Lookup func(name string) (string, bool) `yaml:"-"`
+	// Profiles are the active profiles, services with other profiles are not deployed
+	Profiles []string `yaml:"-"`
+}
+
+// FileReader reads the files referenced by service file, usually from the git repository
+type FileReader interface {
+	ReadFile(name string) ([]byte, error)
 }
 
 type Service struct {
-	Build       string            `yaml:"build"`
+	Build       *Build            `yaml:"build"`
 	Image       string            `yaml:"image"`
-	Ports       []string          `yaml:"ports"`
+	Ports       []Port            `yaml:"ports"`
 	Deploy      Deploy            `yaml:"deploy"`
-	Environment map[string]string `yaml:"environment"`
-	EnvFile     []string          `yaml:"env_file"`
-	Volumes     []string          `yaml:"volumes"`
-	Networks    []string          `yaml:"networks"`
+	Environment MappingWithEquals `yaml:"environment"`
+	EnvFile     StringList        `yaml:"env_file"`
+	Volumes     []ServiceVolume   `yaml:"volumes"`
+	Networks    ServiceNetworks   `yaml:"networks"`
+	Restart     string            `yaml:"restart"` // only used by standalone docker, swarm uses deploy.restart_policy
+	HealthCheck *HealthCheck      `yaml:"healthcheck"`
+	Secrets     []ServiceObject   `yaml:"secrets"`
+	Configs     []ServiceObject   `yaml:"configs"`
+
+	Command         ShellCommand `yaml:"command"`
+	Entrypoint      ShellCommand `yaml:"entrypoint"`
+	WorkingDir      string       `yaml:"working_dir"`
+	User            string       `yaml:"user"`
+	Hostname        string       `yaml:"hostname"`
+	StopGracePeriod string       `yaml:"stop_grace_period"` // like "1m30s"
+	StopSignal      string       `yaml:"stop_signal"`
+	Init            *bool        `yaml:"init"`
+	Tty             bool         `yaml:"tty"`
+	StdinOpen       bool         `yaml:"stdin_open"`
+	ReadOnly        bool         `yaml:"read_only"`
+	Labels          Mapping      `yaml:"labels"` // container labels
+
+	Logging    *Logging          `yaml:"logging"`
+	Ulimits    map[string]Ulimit `yaml:"ulimits"`
+	Sysctls    Mapping           `yaml:"sysctls"`
+	CapAdd     []string          `yaml:"cap_add"`
+	CapDrop    []string          `yaml:"cap_drop"`
+	ExtraHosts ExtraHosts        `yaml:"extra_hosts"`
+
+	Profiles []string `yaml:"profiles"` // service is only deployed when one of them is active
 }
 
 type Deploy struct {
-	Mode     string `yaml:"mode"`
-	Replicas uint64 `yaml:"replicas"`
-}
-
-type Network struct {
-	Name   string            `yaml:"name"`
-	Driver string            `yaml:"driver"`
-	Ipam   map[string]string `yaml:"ipam"`
-}
-
-type Volume struct {
-	Name       string            `yaml:"name"`
-	Driver     string            `yaml:"driver"`
-	DriverOpts map[string]string `yaml:"driver_opts"`
-	Labels     []string          `yaml:"labels"`
-	Options    map[string]string `yaml:"options"`
+	Mode      string     `yaml:"mode"`     // replicated (default), global, replicated-job or global-job
+	Replicas  *uint64    `yaml:"replicas"` // 1 by default
+	Resources *Resources `yaml:"resources"`
+	Placement *Placement `yaml:"placement"`
+	Labels    Mapping    `yaml:"labels"` // service labels
+
+	UpdateConfig   *UpdateConfig  `yaml:"update_config"`
+	RollbackConfig *UpdateConfig  `yaml:"rollback_config"`
+	RestartPolicy  *RestartPolicy `yaml:"restart_policy"`
+
+	EndpointMode     string  `yaml:"endpoint_mode"`     // vip (default) or dnsrr
+	MaxConcurrent    *uint64 `yaml:"max_concurrent"`    // only for replicated-job, defaults to replicas
+	TotalCompletions *uint64 `yaml:"total_completions"` // only for replicated-job, defaults to replicas
 }
 
-func (d *DockerSwarm) GetServiceSpec(appName string, networkID string) ([]swarm.ServiceSpec, error) {
+// GetServiceSpec makes the swarm service specs, networkIDs are the networks from GetNetworkSpecs
+// and objects are the secrets and configs already created in swarm for the application.
+func (d *DockerSwarm) GetServiceSpec(appName string, networkIDs map[string]string, objects Objects) ([]swarm.ServiceSpec, error) {
 	slog.Info("Getting service spec for app", "app name", appName)
 
 	specs := make([]swarm.ServiceSpec, 0)
 
 	for serviceName, spec := range d.Services {
 		spec := spec
+
+		if !d.isEnabled(spec) {
+			slog.Info("Skipping service, none of its profiles is active", "service_name", serviceName, "profiles", spec.Profiles)
+			continue
+		}
+
 		slog.Info("Making serviceSpec for service", "service_name", serviceName)
 
 		var targetSpec swarm.ServiceSpec
@@ -82,110 +126,113 @@ func (d *DockerSwarm) GetServiceSpec(appName string, networkID string) ([]swarm.
 		// Name of service like "stackName_serviceName"
 		targetSpec.Name = appName + "_" + serviceName
 
-		// Labels
-		targetSpec.Labels = map[string]string{
-			"com.docker.stack.image":     spec.Image,
-			"com.docker.stack.namespace": appName,
+		// Labels, the namespace labels are set last so that they can not be overridden
+		targetSpec.Labels = make(map[string]string)
+		for k, v := range spec.Deploy.Labels {
+			targetSpec.Labels[k] = v
 		}
+		targetSpec.Labels["com.docker.stack.image"] = spec.Image
+		targetSpec.Labels["com.docker.stack.namespace"] = appName
+
+		containerLabels := make(map[string]string)
+		for k, v := range spec.Labels {
+			containerLabels[k] = v
+		}
+		containerLabels["com.docker.stack.namespace"] = appName
 
 		targetSpec.TaskTemplate = swarm.TaskSpec{
 			ContainerSpec: &swarm.ContainerSpec{
-				Image: spec.Image,
-				Labels: map[string]string{
-					"com.docker.stack.namespace": appName,
-				},
+				Image:  spec.Image,
+				Labels: containerLabels,
 			},
 		}
 
-		// Connection the service with the network
-		targetSpec.TaskTemplate.Networks = append(targetSpec.TaskTemplate.Networks, swarm.NetworkAttachmentConfig{
-			Target: networkID,
-			Aliases: []string{
-				serviceName,
-			},
-		})
-
-		for _, envFile := range spec.EnvFile {
-			slog.Info("Using environment variable from files", "file", envFile)
+		if err := spec.setContainerOptions(targetSpec.TaskTemplate.ContainerSpec); err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "", err)
+		}
 
-			envVars, err := getEnvVars(envFile)
-			if err != nil {
-				return []swarm.ServiceSpec{}, err
-			}
+		targetSpec.TaskTemplate.LogDriver = spec.Logging.getLogDriver()
 
-			slog.Info("Found environment from file", "count", len(envVars))
+		healthConfig, err := spec.HealthCheck.getHealthConfig()
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "healthcheck", err)
+		}
+		targetSpec.TaskTemplate.ContainerSpec.Healthcheck = healthConfig
 
-			for k, v := range envVars {
-				targetSpec.TaskTemplate.ContainerSpec.Env = append(targetSpec.TaskTemplate.ContainerSpec.Env, k+"="+v)
-			}
+		resources, err := spec.Deploy.Resources.getResourceRequirements()
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.resources", err)
 		}
+		targetSpec.TaskTemplate.Resources = resources
 
-		for k, v := range spec.Environment {
-			targetSpec.TaskTemplate.ContainerSpec.Env = append(targetSpec.TaskTemplate.ContainerSpec.Env, k+"="+v)
+		placement, err := spec.Deploy.Placement.getPlacement()
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.placement", err)
 		}
+		targetSpec.TaskTemplate.Placement = placement
 
-		for _, m := range spec.Volumes {
-			tokens := strings.SplitN(m, ":", 2)
-			if len(tokens) != 2 {
-				slog.Error("Volumes are not split on : in 2", "tokens", tokens)
-				return []swarm.ServiceSpec{}, errors.New("invalid volumes")
-			}
+		targetSpec.UpdateConfig, err = spec.Deploy.UpdateConfig.getUpdateConfig("update_config",
+			swarm.UpdateFailureActionPause, swarm.UpdateFailureActionContinue, swarm.UpdateFailureActionRollback)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.update_config", err)
+		}
 
-			key := tokens[0]
-			value := tokens[1]
+		targetSpec.RollbackConfig, err = spec.Deploy.RollbackConfig.getUpdateConfig("rollback_config",
+			swarm.UpdateFailureActionPause, swarm.UpdateFailureActionContinue)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.rollback_config", err)
+		}
 
-			volumeType := mount.TypeVolume // volume mount
+		targetSpec.TaskTemplate.RestartPolicy, err = spec.Deploy.RestartPolicy.getRestartPolicy()
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.restart_policy", err)
+		}
 
-			// checking for Bind mounts
-			if strings.HasPrefix(key, ".") ||
-				strings.HasPrefix(key, "~") ||
-				strings.HasPrefix(key, "/") {
-				absPath, err := normalizeFilePath(key)
-				if err != nil {
-					return []swarm.ServiceSpec{}, err
-				}
+		targetSpec.TaskTemplate.ContainerSpec.Secrets, err = getSecretReferences(spec.Secrets, objects.Secrets)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "secrets", err)
+		}
 
-				key = absPath
-				volumeType = mount.TypeBind
-			}
+		targetSpec.TaskTemplate.ContainerSpec.Configs, err = getConfigReferences(spec.Configs, objects.Configs)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "configs", err)
+		}
 
-			targetSpec.TaskTemplate.ContainerSpec.Mounts = append(targetSpec.TaskTemplate.ContainerSpec.Mounts, mount.Mount{
-				Type:   volumeType,
-				Source: key,
-				Target: value,
-			})
+		// Connection the service with the networks
+		targetSpec.TaskTemplate.Networks, err = spec.getNetworkAttachments(serviceName, networkIDs)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "networks", err)
+		}
 
-			slog.Info("Using volume", "key", key, "value", value)
+		env, err := d.getEnv(spec)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "", err)
 		}
+		targetSpec.TaskTemplate.ContainerSpec.Env = env
 
-		if spec.Deploy.Mode == "replicated" {
-			targetSpec.Mode.Replicated = &swarm.ReplicatedService{
-				Replicas: &spec.Deploy.Replicas,
+		for _, v := range spec.Volumes {
+			m, err := v.getMount(appName, d.Volumes, d.AllowHostPaths)
+			if err != nil {
+				return []swarm.ServiceSpec{}, serviceError(serviceName, "volumes", err)
 			}
-		} else if spec.Deploy.Mode == "global" {
-			targetSpec.Mode.Global = &swarm.GlobalService{}
+
+			targetSpec.TaskTemplate.ContainerSpec.Mounts = append(targetSpec.TaskTemplate.ContainerSpec.Mounts, m)
+			slog.Info("Using volume", "type", m.Type, "source", m.Source, "target", m.Target)
 		}
 
-		var ports []swarm.PortConfig
-		for _, port := range spec.Ports {
-			tokens := strings.Split(port, ":")
-			if len(tokens) != 2 {
-				slog.Error("ports are not split on : in 2", "tokens", tokens)
-				os.Exit(1)
-			}
-			target, _ := strconv.Atoi(tokens[1])
-			publish, _ := strconv.Atoi(tokens[0])
+		targetSpec.Mode, err = spec.Deploy.getServiceMode()
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.mode", err)
+		}
 
-			ports = append(ports, swarm.PortConfig{
-				Protocol:      "tcp",
-				TargetPort:    uint32(target),
-				PublishedPort: uint32(publish),
-				PublishMode:   swarm.PortConfigPublishModeIngress,
-			})
+		ports, err := getSwarmPorts(serviceName, spec.Ports)
+		if err != nil {
+			return []swarm.ServiceSpec{}, err
 		}
 
-		targetSpec.EndpointSpec = &swarm.EndpointSpec{
-			Ports: ports,
+		targetSpec.EndpointSpec, err = spec.Deploy.getEndpointSpec(ports)
+		if err != nil {
+			return []swarm.ServiceSpec{}, serviceError(serviceName, "deploy.endpoint_mode", err)
 		}
 
 		slog.Info("Adding serviceSpec for service in allServiceArray", "service_name", serviceName)
@@ -195,53 +242,120 @@ func (d *DockerSwarm) GetServiceSpec(appName string, networkID string) ([]swarm.
 	return specs, nil
 }
 
-func getEnvVars(fileName string) (map[string]string, error) {
-	result := make(map[string]string)
+// getEnv returns the sorted environment of service, environment overrides the env_file
+func (d *DockerSwarm) getEnv(spec Service) ([]string, error) {
+	vars := make(map[string]string)
 
-	fileName, err := normalizeFilePath(fileName)
-	if err != nil {
-		return map[string]string{}, err
+	for _, envFile := range spec.EnvFile {
+		slog.Info("Using environment variable from files", "file", envFile)
+
+		envVars, err := d.readEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+
+		slog.Info("Found environment from file", "count", len(envVars))
+
+		for k, v := range envVars {
+			vars[k] = v
+		}
 	}
 
-	fileData, err := os.Open(fileName)
-	if err != nil {
-		slog.Warn("file path does not exist", "file", fileName)
-		fileName, _ = strings.CutPrefix(fileName, "/home")
+	for k, v := range spec.Environment {
+		if v != nil {
+			vars[k] = *v
+			continue
+		}
 
-		fileData, err = os.Open(fileName)
-		if err != nil {
-			slog.Warn("file path does not exist", "file", fileName)
-			return map[string]string{}, err
+		value, found := "", false
+		if d.Lookup != nil {
+			value, found = d.Lookup(k)
 		}
+
+		if !found {
+			slog.Warn("Environment variable without value is not set", "variable", k)
+			delete(vars, k)
+			continue
+		}
+		vars[k] = value
 	}
-	defer fileData.Close()
 
-	scanner := bufio.NewScanner(fileData)
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		tokens := strings.SplitN(line, "=", 2)
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+vars[k])
+	}
 
-		if len(tokens) == 2 {
-			key := strings.TrimSpace(tokens[0])
-			value := strings.TrimSpace(tokens[1])
+	return env, nil
+}
 
-			result[key] = value
+// readEnvFile reads the env_file from repository, or from meltcd host for
+// absolute and home paths when host paths are allowed.
+func (d *DockerSwarm) readEnvFile(file string) (map[string]string, error) {
+	if isHostPath(file) {
+		if !d.AllowHostPaths {
+			return nil, fmt.Errorf("env_file %s is a host path, enable allow_host_paths to use it", file)
 		}
+
+		return getEnvVars(file)
+	}
+
+	if d.Files == nil {
+		return nil, fmt.Errorf("env_file %s: repository files are not available", file)
+	}
+
+	data, err := d.Files.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("env_file %s: %w", file, err)
+	}
+
+	vars, err := ParseDotEnv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("env_file %s: %w", file, err)
 	}
 
-	return result, err
+	return vars, nil
 }
 
-func normalizeFilePath(fileName string) (string, error) {
-	currentUser, err := user.Current()
+func isHostPath(file string) bool {
+	return strings.HasPrefix(file, "/") || strings.HasPrefix(file, "~")
+}
+
+func getEnvVars(fileName string) (map[string]string, error) {
+	fileName, err := normalizeFilePath(fileName)
 	if err != nil {
-		return "", err
+		return map[string]string{}, err
 	}
 
-	username := currentUser.Username
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		slog.Warn("file path does not exist", "file", fileName)
+		return map[string]string{}, err
+	}
+
+	vars, err := ParseDotEnv(string(data))
+	if err != nil {
+		return map[string]string{}, fmt.Errorf("env_file %s: %w", fileName, err)
+	}
+
+	return vars, nil
+}
 
-	fileName = strings.ReplaceAll(fileName, "~", fmt.Sprintf("/home/%s", username))
+// normalizeFilePath expands "~" to home directory of user and makes the path absolute
+func normalizeFilePath(fileName string) (string, error) {
+	if fileName == "~" || strings.HasPrefix(fileName, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		fileName = filepath.Join(home, fileName[1:])
+	}
 
 	absFilePath, err := filepath.Abs(fileName)
 	if err != nil {
@@ -78,10 +78,76 @@ func TestGetEnvVars(t *testing.T) {
 		t.Error(err.Error())
 	}
 
-	if res["ENV_1"] != "\"1\"" ||
-		res["ENV_2"] != "\"2\"" ||
+	if res["ENV_1"] != "1" ||
+		res["ENV_2"] != "2" ||
 		res["ENV_3"] != "3" ||
 		res["ENV_4"] != "4" {
 		t.Error("failed to convert env file into map[string]string", res)
 	}
 }
+
+// mapFiles is the repository files for tests
+type mapFiles map[string]string
+
+func (f mapFiles) ReadFile(name string) ([]byte, error) {
+	content, found := f[name]
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func TestEnvFileFromRepository(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {Image: "nginx", EnvFile: []string{"config/web.env"}},
+		},
+		Files: mapFiles{"config/web.env": "PORT=8080\n#DEBUG=true\nexport NAME=\"web app\" \n"},
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	env := services[0].TaskTemplate.ContainerSpec.Env
+	if len(env) != 2 || env[0] != "NAME=web app" || env[1] != "PORT=8080" {
+		t.Errorf("env_file is not read from repository: %v", env)
+	}
+
+	d.Services["web"] = Service{Image: "nginx", EnvFile: []string{"missing.env"}}
+	if _, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{}); err == nil {
+		t.Error("expected error for env_file missing in repository")
+	}
+}
+
+func TestHostPathsNotAllowed(t *testing.T) {
+	networks := map[string]string{DefaultNetwork: "net"}
+
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {Image: "nginx", EnvFile: []string{"/etc/web.env"}},
+		},
+		Files: mapFiles{},
+	}
+
+	if _, err := d.GetServiceSpec("app", networks, Objects{}); err == nil {
+		t.Error("expected error for env_file on host without allow_host_paths")
+	}
+
+	d.Services["web"] = Service{Image: "nginx", Volumes: []ServiceVolume{{Type: "bind", Source: "./data", Target: "/data"}}}
+	if _, err := d.GetServiceSpec("app", networks, Objects{}); err == nil {
+		t.Error("expected error for relative bind mount without allow_host_paths")
+	}
+
+	d.AllowHostPaths = true
+	if _, err := d.GetServiceSpec("app", networks, Objects{}); err != nil {
+		t.Errorf("relative bind mount must be allowed with allow_host_paths: %s", err.Error())
+	}
+
+	d.Services["web"] = Service{Image: "nginx", Volumes: []ServiceVolume{{Type: "bind", Source: "/var/run/docker.sock", Target: "/var/run/docker.sock"}}}
+	d.AllowHostPaths = false
+	if _, err := d.GetServiceSpec("app", networks, Objects{}); err != nil {
+		t.Errorf("absolute bind mount must be allowed: %s", err.Error())
+	}
+}
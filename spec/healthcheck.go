@@ -0,0 +1,111 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+type HealthCheck struct {
+	Test          HealthCheckTest `yaml:"test"`
+	Interval      string          `yaml:"interval"`
+	Timeout       string          `yaml:"timeout"`
+	Retries       *uint64         `yaml:"retries"`
+	StartPeriod   string          `yaml:"start_period"`
+	StartInterval string          `yaml:"start_interval"`
+	Disable       bool            `yaml:"disable"`
+}
+
+// HealthCheckTest is the test command, list like ["CMD", "curl", "-f", "http://localhost"]
+// or a string which is run with the shell (same as ["CMD-SHELL", "curl -f http://localhost"])
+type HealthCheckTest []string
+
+func (t *HealthCheckTest) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var shell string
+	if err := unmarshal(&shell); err == nil {
+		*t = []string{"CMD-SHELL", shell}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	*t = list
+	return nil
+}
+
+// getHealthConfig returns nil when there is no healthcheck,
+// so that the healthcheck of the image is used
+func (h *HealthCheck) getHealthConfig() (*container.HealthConfig, error) {
+	if h == nil {
+		return nil, nil
+	}
+
+	if h.Disable {
+		return &container.HealthConfig{
+			Test: []string{"NONE"},
+		}, nil
+	}
+
+	if len(h.Test) != 0 {
+		switch h.Test[0] {
+		case "NONE", "CMD", "CMD-SHELL":
+		default:
+			return nil, fmt.Errorf("healthcheck test must start with NONE, CMD or CMD-SHELL, found %q", h.Test[0])
+		}
+	}
+
+	config := &container.HealthConfig{
+		Test: h.Test,
+	}
+
+	var err error
+
+	if config.Interval, err = parseDuration("healthcheck interval", h.Interval); err != nil {
+		return nil, err
+	}
+
+	if config.Timeout, err = parseDuration("healthcheck timeout", h.Timeout); err != nil {
+		return nil, err
+	}
+
+	if config.StartPeriod, err = parseDuration("healthcheck start_period", h.StartPeriod); err != nil {
+		return nil, err
+	}
+
+	if config.StartInterval, err = parseDuration("healthcheck start_interval", h.StartInterval); err != nil {
+		return nil, err
+	}
+
+	for _, d := range []time.Duration{config.Interval, config.Timeout, config.StartPeriod, config.StartInterval} {
+		if d != 0 && d < container.MinimumDuration {
+			return nil, errors.New("healthcheck durations must be at least " + container.MinimumDuration.String())
+		}
+	}
+
+	if h.Retries != nil {
+		config.Retries = int(*h.Retries)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var svc Service
+
+	err := yaml.Unmarshal([]byte(`
+healthcheck:
+  test: curl -f http://localhost
+  interval: 1m30s
+  timeout: 10s
+  retries: 3
+  start_period: 40s
+`), &svc)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config, err := svc.HealthCheck.getHealthConfig()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(config.Test) != 2 || config.Test[0] != "CMD-SHELL" || config.Test[1] != "curl -f http://localhost" {
+		t.Error("string test is not converted to CMD-SHELL", config.Test)
+	}
+
+	if config.Interval != 90*time.Second || config.Timeout != 10*time.Second ||
+		config.StartPeriod != 40*time.Second || config.Retries != 3 {
+		t.Error("wrong healthcheck config", config)
+	}
+
+	disabled := HealthCheck{Disable: true}
+	config, _ = disabled.getHealthConfig()
+	if len(config.Test) != 1 || config.Test[0] != "NONE" {
+		t.Error("disabled healthcheck must be NONE", config.Test)
+	}
+
+	invalid := HealthCheck{Test: []string{"curl"}, Interval: "10"}
+	if _, err := invalid.getHealthConfig(); err == nil {
+		t.Error("invalid healthcheck is not rejected")
+	}
+
+	var none *HealthCheck
+	if config, _ := none.getHealthConfig(); config != nil {
+		t.Error("missing healthcheck must use the image healthcheck")
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Interpolate replaces the variables in the values of service file like docker compose does,
+// supporting $VAR, ${VAR}, ${VAR:-default}, ${VAR-default}, ${VAR:?error},
+// ${VAR?error}, ${VAR:+replacement}, ${VAR+replacement} and "$$" for a literal "$".
+// Only the parsed values are interpolated, so comments and keys are left as they are
+// and a substituted value can not change the structure of the file.
+func Interpolate(node *yaml.Node, lookup func(name string) (string, bool)) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := Interpolate(child, lookup); err != nil {
+				return err
+			}
+		}
+
+	case yaml.MappingNode:
+		// keys are not interpolated
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := Interpolate(node.Content[i], lookup); err != nil {
+				return err
+			}
+		}
+
+	case yaml.ScalarNode:
+		value, err := interpolateString(node.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+
+		if value == node.Value {
+			return nil
+		}
+		node.Value = value
+
+		// unquoted values get their type from the substituted value, like "replicas: ${REPLICAS}"
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+
+	return nil
+}
+
+func interpolateString(s string, lookup func(string) (string, bool)) (string, error) {
+	var out strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		next := s[i+1]
+
+		switch {
+		case next == '$':
+			out.WriteByte('$')
+			i++
+
+		case next == '{':
+			end := closingBrace(s, i+1)
+			if end == -1 {
+				return "", fmt.Errorf("missing closing brace in %q", s[i:])
+			}
+
+			value, err := expand(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+
+			out.WriteString(value)
+			i = end
+
+		case isNameStart(next):
+			end := i + 1
+			for end < len(s) && isNameChar(s[end]) {
+				end++
+			}
+
+			value, found := lookup(s[i+1 : end])
+			if !found {
+				slog.Warn("Variable is not set, using empty string", "variable", s[i+1:end])
+			}
+
+			out.WriteString(value)
+			i = end - 1
+
+		default:
+			out.WriteByte('$')
+		}
+	}
+
+	return out.String(), nil
+}
+
+// expand evaluates the expression inside "${}"
+func expand(expr string, lookup func(string) (string, bool)) (string, error) {
+	end := 0
+	for end < len(expr) && isNameChar(expr[end]) {
+		end++
+	}
+
+	name := expr[:end]
+	if name == "" || !isNameStart(name[0]) {
+		return "", fmt.Errorf("invalid interpolation format for \"${%s}\"", expr)
+	}
+
+	value, found := lookup(name)
+	rest := expr[end:]
+
+	if rest == "" {
+		if !found {
+			slog.Warn("Variable is not set, using empty string", "variable", name)
+		}
+		return value, nil
+	}
+
+	for _, op := range []string{":-", ":?", ":+", "-", "?", "+"} {
+		word, ok := strings.CutPrefix(rest, op)
+		if !ok {
+			continue
+		}
+
+		// with ":" empty variables are treated as unset
+		set := found
+		if op[0] == ':' {
+			set = found && value != ""
+		}
+
+		switch op[len(op)-1] {
+		case '-':
+			if set {
+				return value, nil
+			}
+			return interpolateString(word, lookup)
+		case '?':
+			if set {
+				return value, nil
+			}
+
+			message, err := interpolateString(word, lookup)
+			if err != nil {
+				return "", err
+			}
+			if message == "" {
+				return "", fmt.Errorf("required variable %s is missing a value", name)
+			}
+			return "", fmt.Errorf("required variable %s is missing a value: %s", name, message)
+		case '+':
+			if !set {
+				return "", nil
+			}
+			return interpolateString(word, lookup)
+		}
+	}
+
+	return "", fmt.Errorf("invalid interpolation format for \"${%s}\"", expr)
+}
+
+// closingBrace returns the index of "}" closing the "{" at start, with nested braces
+func closingBrace(s string, start int) int {
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// ParseDotEnv parses the ".env" file used for interpolation, lines are like
+// "KEY=value" or "export KEY=value", quotes around values are removed.
+func ParseDotEnv(content string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line, _ = strings.CutPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: invalid variable %q, must be like KEY=value", lineNumber, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if index := strings.Index(value, " #"); index != -1 {
+			// inline comment for unquoted values
+			value = strings.TrimSpace(value[:index])
+		}
+
+		result[key] = value
+	}
+
+	return result, scanner.Err()
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInterpolateString(t *testing.T) {
+	variables := map[string]string{
+		"TAG":     "1.2.0",
+		"DB_HOST": "db",
+		"EMPTY":   "",
+	}
+
+	lookup := func(name string) (string, bool) {
+		value, found := variables[name]
+		return value, found
+	}
+
+	tests := map[string]string{
+		"app:${TAG}":                    "app:1.2.0",
+		"$DB_HOST:5432":                 "db:5432",
+		"app:${MISSING:-latest}":        "app:latest",
+		"app:${EMPTY:-latest}":          "app:latest",
+		"app:${EMPTY-latest}":           "app:",
+		"${TAG:+true}":                  "true",
+		"${MISSING:+true}":              "",
+		"${EMPTY+set}":                  "set",
+		"$$5":                           "$5",
+		"${MISSING:-http://${DB_HOST}}": "http://db",
+		"$MISSING.":                     ".",
+		"$ 1":                           "$ 1",
+	}
+
+	for input, expected := range tests {
+		res, err := interpolateString(input, lookup)
+		if err != nil {
+			t.Errorf("%q: %s", input, err.Error())
+			continue
+		}
+
+		if res != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, res)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string]string{
+		"REPLICAS": "3",
+		"TAG":      "1.2.0",
+		"INJECT":   "x\nprivileged: true",
+		"COLON":    "a: b",
+	}
+
+	lookup := func(name string) (string, bool) {
+		value, found := variables[name]
+		return value, found
+	}
+
+	content := `# use ${TAG without closing brace in comments
+services:
+  web:
+    image: app:${TAG} # ${ in comment
+    command: echo "$INJECT"
+    hostname: ${COLON}
+    deploy:
+      replicas: ${REPLICAS}
+    labels:
+      replicas: "${REPLICAS}"
+      ${TAG}: key
+`
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := Interpolate(&root, lookup); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var res struct {
+		Services map[string]map[string]interface{} `yaml:"services"`
+	}
+	if err := root.Decode(&res); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	web := res.Services["web"]
+	if web["image"] != "app:1.2.0" || web["hostname"] != "a: b" || web["privileged"] != nil {
+		t.Error("values are not interpolated as values", web)
+	}
+
+	if web["command"] != "echo \"x\nprivileged: true\"" {
+		t.Error("value with newline is not kept in the value", web["command"])
+	}
+
+	if web["deploy"].(map[string]interface{})["replicas"] != 3 {
+		t.Error("unquoted value does not get the type of substituted value", web["deploy"])
+	}
+
+	labels := web["labels"].(map[string]interface{})
+	if labels["replicas"] != "3" || labels["${TAG}"] != "key" {
+		t.Error("quoted value must be a string and keys are not interpolated", labels)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		return "", name == "EMPTY"
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte("image: app\npassword: ${DB_PASSWORD:?must be set}\n"), &root); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err := Interpolate(&root, lookup)
+	if err == nil || !strings.Contains(err.Error(), "line 2") || !strings.Contains(err.Error(), "must be set") {
+		t.Errorf("expected required variable error on line 2, got %v", err)
+	}
+
+	if _, err := interpolateString("${EMPTY:?}", lookup); err == nil {
+		t.Error("expected error for empty required variable")
+	}
+
+	if _, err := interpolateString("${EMPTY?}", lookup); err != nil {
+		t.Errorf("empty variable is set: %s", err.Error())
+	}
+
+	invalid := []string{"${TAG", "${}", "${1TAG}", "${TAG:x}"}
+	for _, input := range invalid {
+		if _, err := interpolateString(input, lookup); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	res, err := ParseDotEnv(`
+# Comment
+TAG=1.2.0
+export DB_HOST = db
+QUOTED="hello world"
+SINGLE='a # b'
+INLINE=value # comment
+EMPTY=
+`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := map[string]string{
+		"TAG":     "1.2.0",
+		"DB_HOST": "db",
+		"QUOTED":  "hello world",
+		"SINGLE":  "a # b",
+		"INLINE":  "value",
+		"EMPTY":   "",
+	}
+
+	for k, v := range expected {
+		if res[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, res[k])
+		}
+	}
+
+	if _, err := ParseDotEnv("NOT A VARIABLE"); err == nil {
+		t.Error("expected error for line without =")
+	}
+}
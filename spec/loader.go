@@ -0,0 +1,659 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Loader reads the service files from repository and merges them in order
+// with docker compose override semantics, resolving "include" and "extends".
+type Loader struct {
+	Files  FileReader                       // files relative to the repository root
+	Lookup func(name string) (string, bool) // variables for interpolation, nil means no interpolation
+	Values map[string]interface{}           // values for the service files which are go templates
+
+	// Warnings are the unsupported keys found by Load, they are ignored
+	Warnings ValidationErrors
+
+	// lines are the files and lines of the keys like "services.web.ports", of the last file setting them
+	lines map[string]ValidationError
+}
+
+// KeyError is an error of the specs made from service file, at the key like "services.web.ports"
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// serviceError is the error of service at the key of service like "healthcheck"
+func serviceError(serviceName, key string, err error) error {
+	return &KeyError{
+		Key: joinKeyPath("services."+serviceName, key),
+		Err: fmt.Errorf("service %s: %w", serviceName, err),
+	}
+}
+
+// Locate reports the KeyError of specs made from the loaded files with the file and line
+// of the key, or of its closest parent key. Other errors are returned as they are.
+func (l *Loader) Locate(err error) error {
+	var keyError *KeyError
+	if !errors.As(err, &keyError) {
+		return err
+	}
+
+	for key := keyError.Key; key != ""; {
+		if position, found := l.lines[key]; found {
+			return ValidationErrors{{File: position.File, Line: position.Line, Message: err.Error()}}
+		}
+
+		index := strings.LastIndex(key, ".")
+		if index < 0 {
+			break
+		}
+		key = key[:index]
+	}
+
+	return err
+}
+
+// document is the mapping node of a service file, the files are merged as yaml nodes
+// so that the scalars keep their text, like "3.10" or "0755", until they are decoded.
+type document = *yamlv3.Node
+
+// Load merges the service files, later files override the earlier ones.
+// Relative paths are resolved from the directory of the first file.
+func (l *Loader) Load(paths []string) (DockerSwarm, error) {
+	if len(paths) == 0 {
+		return DockerSwarm{}, fmt.Errorf("no service file specified")
+	}
+
+	if l.Files == nil {
+		return DockerSwarm{}, fmt.Errorf("repository files are not available")
+	}
+
+	mainDir := path.Dir(paths[0])
+	l.Warnings = nil
+	l.lines = make(map[string]ValidationError)
+
+	merged := newMapping()
+	for _, p := range paths {
+		doc, err := l.loadFile(p, mainDir, nil)
+		if err != nil {
+			return DockerSwarm{}, err
+		}
+
+		merged = mergeDocuments(merged, doc)
+	}
+
+	content, err := yamlv3.Marshal(merged)
+	if err != nil {
+		return DockerSwarm{}, err
+	}
+
+	var d DockerSwarm
+	if err := yaml.Unmarshal(content, &d); err != nil {
+		return DockerSwarm{}, err
+	}
+	d.Lookup = l.Lookup
+
+	return d, nil
+}
+
+// loadFile reads the file with its includes and extends resolved
+func (l *Loader) loadFile(file, mainDir string, stack []string) (document, error) {
+	for _, f := range stack {
+		if f == file {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, file), " -> "))
+		}
+	}
+	stack = append(stack, file)
+
+	doc, err := l.readFile(file, mainDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.resolveExtends(doc, file, mainDir); err != nil {
+		return nil, err
+	}
+
+	includes, err := includePaths(valueOf(doc, "include"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	deleteKey(doc, "include")
+
+	for _, inc := range includes {
+		included, err := l.loadFile(path.Join(path.Dir(file), inc), mainDir, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, section := range []string{"services", "networks", "volumes", "secrets", "configs"} {
+			existing := asDocument(valueOf(doc, section))
+			for _, name := range keys(asDocument(valueOf(included, section))) {
+				if valueOf(existing, name) != nil {
+					return nil, fmt.Errorf("%s: %s %v is also defined in included file %s", file, section, name, inc)
+				}
+			}
+		}
+
+		doc = mergeDocuments(included, doc)
+	}
+
+	return doc, nil
+}
+
+// readFile reads, renders the template, parses and interpolates the file,
+// relative paths in it are rebased on mainDir
+func (l *Loader) readFile(file, mainDir string) (document, error) {
+	data, err := l.Files.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	// lines are of the file, except for templates where they are of the rendered template
+	name := file
+
+	content := string(data)
+	if IsTemplate(file) {
+		content, err = RenderTemplate(path.Base(file), content, l.Values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		name = file + " (rendered)"
+	}
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(content), &root); err != nil {
+		return nil, lineError(name, err)
+	}
+
+	if l.Lookup != nil {
+		if err := Interpolate(&root, l.Lookup); err != nil {
+			return nil, lineError(name, err)
+		}
+	}
+
+	errs, warnings := ValidateNode(name, &root)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	l.Warnings = append(l.Warnings, warnings...)
+
+	doc := newMapping()
+	if len(root.Content) > 0 {
+		doc = plainNode(root.Content[0])
+		l.recordLines(name, "", doc)
+	}
+
+	if err := rebasePaths(doc, path.Dir(file), mainDir); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return doc, nil
+}
+
+// resolveExtends replaces the services having "extends" with the merge of extended service
+func (l *Loader) resolveExtends(doc document, file, mainDir string) error {
+	services := asDocument(valueOf(doc, "services"))
+
+	for _, name := range keys(services) {
+		resolved, err := l.extendService(services, file, mainDir, name, nil)
+		if err != nil {
+			return fmt.Errorf("%s: service %v: %w", file, name, err)
+		}
+		setKey(services, name, resolved)
+	}
+
+	return nil
+}
+
+func (l *Loader) extendService(services document, file, mainDir, name string, visiting []string) (document, error) {
+	key := file + "#" + name
+	for _, v := range visiting {
+		if v == key {
+			return nil, fmt.Errorf("extends cycle: %s", strings.Join(append(visiting, key), " -> "))
+		}
+	}
+	visiting = append(visiting, key)
+
+	raw := valueOf(services, name)
+	if raw == nil {
+		return nil, fmt.Errorf("service %s not found in %s", name, file)
+	}
+	svc := asDocument(raw)
+
+	ext := valueOf(svc, "extends")
+	if ext == nil {
+		return svc, nil
+	}
+
+	var baseName, baseFile string
+	switch ext.Kind {
+	case yamlv3.ScalarNode:
+		baseName = ext.Value
+	case yamlv3.MappingNode:
+		baseName = scalarOf(valueOf(ext, "service"))
+		baseFile = scalarOf(valueOf(ext, "file"))
+	}
+
+	if baseName == "" {
+		return nil, fmt.Errorf("extends must have service")
+	}
+
+	baseServices, baseFilePath := services, file
+	if baseFile != "" {
+		baseFilePath = path.Join(path.Dir(file), baseFile)
+
+		baseDoc, err := l.readFile(baseFilePath, mainDir)
+		if err != nil {
+			return nil, err
+		}
+		baseServices = asDocument(valueOf(baseDoc, "services"))
+	}
+
+	base, err := l.extendService(baseServices, baseFilePath, mainDir, baseName, visiting)
+	if err != nil {
+		return nil, err
+	}
+
+	override := copyMapping(svc)
+	deleteKey(override, "extends")
+
+	return mergeService(base, override), nil
+}
+
+// includePaths reads the top level include, like ["a.yml", {path: "b.yml"}, {path: ["c.yml"]}]
+func includePaths(include *yamlv3.Node) ([]string, error) {
+	if include == nil || include.Tag == "!!null" {
+		return nil, nil
+	}
+
+	if include.Kind != yamlv3.SequenceNode {
+		return nil, fmt.Errorf("include must be a list")
+	}
+
+	var paths []string
+	for _, inc := range include.Content {
+		switch inc.Kind {
+		case yamlv3.ScalarNode:
+			paths = append(paths, inc.Value)
+		case yamlv3.MappingNode:
+			p := valueOf(inc, "path")
+			switch {
+			case p != nil && p.Kind == yamlv3.ScalarNode:
+				paths = append(paths, p.Value)
+			case p != nil && p.Kind == yamlv3.SequenceNode:
+				for _, s := range p.Content {
+					paths = append(paths, s.Value)
+				}
+			default:
+				return nil, fmt.Errorf("include must have path")
+			}
+		default:
+			return nil, fmt.Errorf("invalid include at line %d", inc.Line)
+		}
+	}
+
+	return paths, nil
+}
+
+// rebasePaths makes the repository paths of file (env_file, build context, secret and config files)
+// relative to mainDir, since they are read relative to the first service file.
+func rebasePaths(doc document, fileDir, mainDir string) error {
+	if fileDir == mainDir {
+		return nil
+	}
+
+	rel, err := filepath.Rel(mainDir, fileDir)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+
+	rebase := func(n *yamlv3.Node) {
+		if n == nil || n.Kind != yamlv3.ScalarNode || isHostPath(n.Value) {
+			return
+		}
+		n.Value = path.Join(rel, n.Value)
+	}
+
+	for _, s := range asDocument(valueOf(doc, "services")).Content {
+		if s.Kind != yamlv3.MappingNode {
+			continue
+		}
+
+		if build := valueOf(s, "build"); build != nil {
+			if build.Kind == yamlv3.MappingNode {
+				build = valueOf(build, "context")
+			}
+			rebase(build)
+		}
+
+		if envFile := valueOf(s, "env_file"); envFile != nil {
+			if envFile.Kind == yamlv3.SequenceNode {
+				for _, f := range envFile.Content {
+					rebase(f)
+				}
+			}
+			rebase(envFile)
+		}
+	}
+
+	for _, section := range []string{"secrets", "configs"} {
+		for _, o := range asDocument(valueOf(doc, section)).Content {
+			if o.Kind == yamlv3.MappingNode {
+				rebase(valueOf(o, "file"))
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeDocuments merges the override document into base
+func mergeDocuments(base, override document) document {
+	result := copyMapping(base)
+
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		k, v := override.Content[i].Value, override.Content[i+1]
+		if k != "services" {
+			setKey(result, k, mergeValue(valueOf(result, k), v))
+			continue
+		}
+
+		services := copyMapping(asDocument(valueOf(result, "services")))
+		for j := 0; j+1 < len(v.Content) && v.Kind == yamlv3.MappingNode; j += 2 {
+			name, svc := v.Content[j].Value, v.Content[j+1]
+			setKey(services, name, mergeService(asDocument(valueOf(services, name)), asDocument(svc)))
+		}
+		setKey(result, "services", services)
+	}
+
+	return result
+}
+
+// mergeService merges the service like docker compose, command and entrypoint are replaced,
+// environment, labels, networks and sysctls are merged by name, volumes by target,
+// secrets and configs by source and other lists like ports are appended.
+func mergeService(base, override document) document {
+	result := copyMapping(base)
+
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		k, v := override.Content[i].Value, override.Content[i+1]
+
+		switch k {
+		case "command", "entrypoint":
+			setKey(result, k, v)
+		case "environment", "labels", "networks", "sysctls":
+			setKey(result, k, mergeValue(toDocument(valueOf(result, k)), toDocument(v)))
+		case "volumes":
+			setKey(result, k, mergeListBy(valueOf(result, k), v, volumeKey))
+		case "secrets", "configs":
+			setKey(result, k, mergeListBy(valueOf(result, k), v, objectKey))
+		case "ports", "expose", "dns", "dns_search", "tmpfs", "env_file", "cap_add", "cap_drop", "extra_hosts", "profiles":
+			setKey(result, k, mergeListBy(valueOf(result, k), v, itemKey))
+		default:
+			setKey(result, k, mergeValue(valueOf(result, k), v))
+		}
+	}
+
+	return result
+}
+
+// mergeValue merges mappings recursively, other values are replaced
+func mergeValue(base, override *yamlv3.Node) *yamlv3.Node {
+	if base == nil || base.Kind != yamlv3.MappingNode || override.Kind != yamlv3.MappingNode {
+		return override
+	}
+
+	result := copyMapping(base)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		k, v := override.Content[i].Value, override.Content[i+1]
+		if k == "labels" {
+			setKey(result, k, mergeValue(toDocument(valueOf(result, k)), toDocument(v)))
+			continue
+		}
+		setKey(result, k, mergeValue(valueOf(result, k), v))
+	}
+
+	return result
+}
+
+// mergeListBy appends the override items to base, replacing the items with same key
+func mergeListBy(base, override *yamlv3.Node, key func(*yamlv3.Node) string) *yamlv3.Node {
+	if base == nil || base.Kind != yamlv3.SequenceNode || override.Kind != yamlv3.SequenceNode {
+		return override
+	}
+
+	result := &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+	index := make(map[string]int)
+
+	for _, item := range append(append([]*yamlv3.Node{}, base.Content...), override.Content...) {
+		k := key(item)
+		if i, found := index[k]; found {
+			result.Content[i] = item
+			continue
+		}
+
+		index[k] = len(result.Content)
+		result.Content = append(result.Content, item)
+	}
+
+	return result
+}
+
+// toDocument converts list syntax like ["KEY=value", "KEY"] to mapping
+func toDocument(value *yamlv3.Node) *yamlv3.Node {
+	if value == nil || value.Kind != yamlv3.SequenceNode {
+		return value
+	}
+
+	doc := newMapping()
+	for _, item := range value.Content {
+		k, v, found := strings.Cut(item.Value, "=")
+		if !found {
+			// like networks list or "KEY" without value
+			setKey(doc, k, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null"})
+			continue
+		}
+		setKey(doc, k, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: v})
+	}
+
+	return doc
+}
+
+// plainNode copies the node with aliases replaced by the anchored nodes and
+// the "<<" merge keys expanded, so the nodes can be merged with other files
+func plainNode(node *yamlv3.Node) *yamlv3.Node {
+	if node.Kind == yamlv3.AliasNode {
+		return plainNode(node.Alias)
+	}
+
+	result := &yamlv3.Node{
+		Kind:   node.Kind,
+		Style:  node.Style,
+		Tag:    node.Tag,
+		Value:  node.Value,
+		Line:   node.Line,
+		Column: node.Column,
+	}
+
+	if node.Kind != yamlv3.MappingNode {
+		for _, child := range node.Content {
+			result.Content = append(result.Content, plainNode(child))
+		}
+		return result
+	}
+
+	var merges []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "<<" {
+			merges = append(merges, plainNode(node.Content[i+1]))
+			continue
+		}
+		result.Content = append(result.Content, plainNode(node.Content[i]), plainNode(node.Content[i+1]))
+	}
+
+	// keys of the mapping win over the merged keys, and earlier merged mappings over later ones
+	for _, merge := range merges {
+		sources := []*yamlv3.Node{merge}
+		if merge.Kind == yamlv3.SequenceNode {
+			sources = merge.Content
+		}
+
+		for _, source := range sources {
+			for i := 0; i+1 < len(source.Content) && source.Kind == yamlv3.MappingNode; i += 2 {
+				if valueOf(result, source.Content[i].Value) == nil {
+					result.Content = append(result.Content, source.Content[i], source.Content[i+1])
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// recordLines records the lines of the keys of mapping and its child mappings
+func (l *Loader) recordLines(file, keyPath string, mapping *yamlv3.Node) {
+	if l.lines == nil || mapping.Kind != yamlv3.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := joinKeyPath(keyPath, mapping.Content[i].Value)
+		l.lines[key] = ValidationError{File: file, Line: mapping.Content[i].Line}
+		l.recordLines(file, key, mapping.Content[i+1])
+	}
+}
+
+func newMapping() document {
+	return &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+}
+
+// copyMapping copies the keys of mapping, the values are shared
+func copyMapping(mapping document) document {
+	result := newMapping()
+	result.Content = append(result.Content, mapping.Content...)
+	return result
+}
+
+// valueOf is the value of key in mapping, nil when the key is not found
+func valueOf(mapping *yamlv3.Node, key string) *yamlv3.Node {
+	if mapping == nil || mapping.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// setKey replaces the value of key in mapping, or adds the key
+func setKey(mapping document, key string, value *yamlv3.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func deleteKey(mapping document, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func keys(mapping document) []string {
+	var names []string
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		names = append(names, mapping.Content[i].Value)
+	}
+	return names
+}
+
+func asDocument(value *yamlv3.Node) document {
+	if value != nil && value.Kind == yamlv3.MappingNode {
+		return value
+	}
+	return newMapping()
+}
+
+func scalarOf(node *yamlv3.Node) string {
+	if node == nil || node.Kind != yamlv3.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// itemKey is the text of scalar, other items like long syntax ports are compared as whole
+func itemKey(item *yamlv3.Node) string {
+	if item.Kind == yamlv3.ScalarNode {
+		return item.Value
+	}
+
+	content, _ := yamlv3.Marshal(item)
+	return string(content)
+}
+
+func volumeKey(item *yamlv3.Node) string {
+	switch item.Kind {
+	case yamlv3.ScalarNode:
+		if volume, err := parseShortVolume(item.Value); err == nil {
+			return volume.Target
+		}
+	case yamlv3.MappingNode:
+		return scalarOf(valueOf(item, "target"))
+	}
+
+	return itemKey(item)
+}
+
+func objectKey(item *yamlv3.Node) string {
+	if item.Kind == yamlv3.MappingNode {
+		return scalarOf(valueOf(item, "source"))
+	}
+
+	return itemKey(item)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadOverrideFiles(t *testing.T) {
+	files := mapFiles{
+		"deploy/docker-compose.yml": `
+services:
+  web:
+    image: app:${TAG:-latest}
+    command: npm start
+    environment:
+      - LOG_LEVEL=info
+      - PORT=8080
+    ports:
+      - "80:8080"
+    volumes:
+      - data:/data
+    deploy:
+      replicas: 1
+      labels:
+        traefik.enable: "true"
+volumes:
+  data:
+`,
+		"deploy/docker-compose.prod.yml": `
+services:
+  web:
+    command: ["npm", "run", "prod"]
+    environment:
+      LOG_LEVEL: warn
+    ports:
+      - "443:8443"
+    volumes:
+      - /srv/data:/data
+    deploy:
+      replicas: 3
+  worker:
+    image: worker
+`,
+	}
+
+	loader := Loader{
+		Files: files,
+		Lookup: func(name string) (string, bool) {
+			return map[string]string{"TAG": "2.0"}[name], name == "TAG"
+		},
+	}
+
+	d, err := loader.Load([]string{"deploy/docker-compose.yml", "deploy/docker-compose.prod.yml"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	web := d.Services["web"]
+
+	if web.Image != "app:2.0" {
+		t.Errorf("image is not interpolated: %s", web.Image)
+	}
+
+	if !reflect.DeepEqual([]string(web.Command), []string{"npm", "run", "prod"}) {
+		t.Errorf("command must be replaced: %v", web.Command)
+	}
+
+	if envValue(web.Environment, "LOG_LEVEL") != "warn" || envValue(web.Environment, "PORT") != "8080" {
+		t.Errorf("environment must be merged: %v", web.Environment)
+	}
+
+	if len(web.Ports) != 2 {
+		t.Errorf("ports must be appended: %v", web.Ports)
+	}
+
+	if len(web.Volumes) != 1 || web.Volumes[0].Source != "/srv/data" {
+		t.Errorf("volumes must be merged by target: %+v", web.Volumes)
+	}
+
+	if web.Deploy.Replicas == nil || *web.Deploy.Replicas != 3 || web.Deploy.Labels["traefik.enable"] != "true" {
+		t.Errorf("deploy must be merged: %+v", web.Deploy)
+	}
+
+	if _, found := d.Services["worker"]; !found {
+		t.Error("service from override file is missing")
+	}
+}
+
+func TestLoadKeepsScalarText(t *testing.T) {
+	files := mapFiles{
+		"compose.yaml": `
+x-common: &common
+  labels:
+    go.version: 1.20
+services:
+  web:
+    <<: *common
+    image: app
+    environment:
+      PYTHON_VERSION: 3.10
+      MODE: 0755
+      FLAG: yes
+`,
+		"compose.prod.yaml": `
+services:
+  web:
+    environment:
+      - RATIO=1.50
+    labels:
+      enabled: on
+  worker:
+    extends: web
+`,
+	}
+
+	for _, paths := range [][]string{{"compose.yaml"}, {"compose.yaml", "compose.prod.yaml"}} {
+		loader := Loader{Files: files}
+
+		d, err := loader.Load(paths)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		web := d.Services["web"]
+		for key, want := range map[string]string{"PYTHON_VERSION": "3.10", "MODE": "0755", "FLAG": "yes"} {
+			if got := envValue(web.Environment, key); got != want {
+				t.Errorf("%v: environment %s must be %q, got %q", paths, key, want, got)
+			}
+		}
+
+		if web.Labels["go.version"] != "1.20" {
+			t.Errorf("%v: label must be 1.20, got %q", paths, web.Labels["go.version"])
+		}
+
+		if len(paths) == 1 {
+			continue
+		}
+
+		if envValue(web.Environment, "RATIO") != "1.50" || web.Labels["enabled"] != "on" {
+			t.Errorf("merged values must keep their text: %v %v", web.Environment, web.Labels)
+		}
+
+		worker := d.Services["worker"]
+		if envValue(worker.Environment, "RATIO") != "1.50" || worker.Labels["enabled"] != "on" {
+			t.Errorf("extended values must keep their text: %v %v", worker.Environment, worker.Labels)
+		}
+	}
+}
+
+func TestLoadExtendsAndInclude(t *testing.T) {
+	files := mapFiles{
+		"docker-compose.yml": `
+include:
+  - common/db.yml
+services:
+  base:
+    image: app
+    environment:
+      MODE: base
+  web:
+    extends: base
+    environment:
+      ROLE: web
+  worker:
+    extends:
+      file: common/templates.yml
+      service: job
+`,
+		"common/db.yml": `
+services:
+  db:
+    image: postgres
+    env_file: db.env
+`,
+		"common/templates.yml": `
+services:
+  job:
+    image: worker
+    command: run
+`,
+	}
+
+	loader := Loader{Files: files}
+
+	d, err := loader.Load([]string{"docker-compose.yml"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	web := d.Services["web"]
+	if web.Image != "app" || envValue(web.Environment, "MODE") != "base" || envValue(web.Environment, "ROLE") != "web" {
+		t.Errorf("extends in same file is not resolved: %+v", web)
+	}
+
+	if d.Services["worker"].Image != "worker" {
+		t.Errorf("extends from other file is not resolved: %+v", d.Services["worker"])
+	}
+
+	db, found := d.Services["db"]
+	if !found {
+		t.Fatal("included service is missing")
+	}
+
+	if !reflect.DeepEqual(db.EnvFile, StringList{"common/db.env"}) {
+		t.Errorf("env_file of included file is not relative to the service file: %v", db.EnvFile)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	files := mapFiles{
+		"cycle.yml": `
+services:
+  a:
+    extends: b
+  b:
+    extends: a
+`,
+		"include-self.yml": `
+include:
+  - include-self.yml
+`,
+		"conflict.yml": `
+include:
+  - other.yml
+services:
+  web:
+    image: nginx
+`,
+		"other.yml": `
+services:
+  web:
+    image: httpd
+`,
+	}
+
+	loader := Loader{Files: files}
+
+	tests := map[string]string{
+		"cycle.yml":        "extends cycle",
+		"include-self.yml": "include cycle",
+		"conflict.yml":     "also defined",
+		"missing.yml":      "missing.yml",
+	}
+
+	for file, expected := range tests {
+		_, err := loader.Load([]string{file})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error with %q, got %v", file, expected, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+const (
+	ModeReplicated    = "replicated"
+	ModeGlobal        = "global"
+	ModeReplicatedJob = "replicated-job"
+	ModeGlobalJob     = "global-job"
+)
+
+// isJob checks for the services which run to completion
+func (d *Deploy) isJob() bool {
+	return d.Mode == ModeReplicatedJob || d.Mode == ModeGlobalJob
+}
+
+// getServiceMode returns the swarm mode, replicated with 1 replica by default.
+// Jobs run replicas tasks to completion, max_concurrent of them at a time.
+func (d *Deploy) getServiceMode() (swarm.ServiceMode, error) {
+	var mode swarm.ServiceMode
+
+	switch d.Mode {
+	case "", ModeReplicated:
+		replicas := uint64(1)
+		if d.Replicas != nil {
+			replicas = *d.Replicas
+		}
+
+		mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+
+	case ModeGlobal:
+		if d.Replicas != nil {
+			return mode, fmt.Errorf("replicas can not be used with %s mode", d.Mode)
+		}
+
+		mode.Global = &swarm.GlobalService{}
+
+	case ModeReplicatedJob:
+		totalCompletions := uint64(1)
+		if d.TotalCompletions != nil {
+			totalCompletions = *d.TotalCompletions
+		} else if d.Replicas != nil {
+			totalCompletions = *d.Replicas
+		}
+
+		maxConcurrent := totalCompletions
+		if d.MaxConcurrent != nil {
+			maxConcurrent = *d.MaxConcurrent
+		} else if d.Replicas != nil {
+			maxConcurrent = *d.Replicas
+		}
+
+		mode.ReplicatedJob = &swarm.ReplicatedJob{
+			MaxConcurrent:    &maxConcurrent,
+			TotalCompletions: &totalCompletions,
+		}
+
+	case ModeGlobalJob:
+		if d.Replicas != nil || d.MaxConcurrent != nil || d.TotalCompletions != nil {
+			return mode, fmt.Errorf("replicas, max_concurrent and total_completions can not be used with %s mode", d.Mode)
+		}
+
+		mode.GlobalJob = &swarm.GlobalJob{}
+
+	default:
+		return mode, fmt.Errorf("invalid deploy mode %q, must be %s, %s, %s or %s",
+			d.Mode, ModeReplicated, ModeGlobal, ModeReplicatedJob, ModeGlobalJob)
+	}
+
+	if d.isJob() && (d.UpdateConfig != nil || d.RollbackConfig != nil) {
+		return mode, fmt.Errorf("update_config and rollback_config can not be used with %s mode", d.Mode)
+	}
+
+	return mode, nil
+}
+
+// getEndpointSpec returns the endpoint with mode "vip" (default) or "dnsrr",
+// dnsrr services can not publish ports on the ingress routing mesh.
+func (d *Deploy) getEndpointSpec(ports []swarm.PortConfig) (*swarm.EndpointSpec, error) {
+	endpoint := &swarm.EndpointSpec{Ports: ports}
+
+	switch swarm.ResolutionMode(d.EndpointMode) {
+	case "":
+	case swarm.ResolutionModeVIP, swarm.ResolutionModeDNSRR:
+		endpoint.Mode = swarm.ResolutionMode(d.EndpointMode)
+	default:
+		return nil, fmt.Errorf("invalid endpoint_mode %q, must be vip or dnsrr", d.EndpointMode)
+	}
+
+	if endpoint.Mode == swarm.ResolutionModeDNSRR {
+		for _, port := range ports {
+			if port.PublishMode != swarm.PortConfigPublishModeHost {
+				return nil, fmt.Errorf("port %d must use host mode with dnsrr endpoint_mode", port.TargetPort)
+			}
+		}
+	}
+
+	return endpoint, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"gopkg.in/yaml.v2"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestServiceModes(t *testing.T) {
+	var d DockerSwarm
+	err := yaml.Unmarshal([]byte(`
+services:
+  default:
+    image: app
+  scaled-down:
+    image: app
+    deploy:
+      replicas: 0
+  agent:
+    image: agent
+    deploy:
+      mode: global
+      endpoint_mode: dnsrr
+  migrate:
+    image: app
+    deploy:
+      mode: replicated-job
+      replicas: 3
+      max_concurrent: 1
+  prune:
+    image: docker
+    deploy:
+      mode: global-job
+`), &d)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, service := range services {
+		mode := service.Mode
+
+		switch service.Name {
+		case "app_default":
+			if mode.Replicated == nil || *mode.Replicated.Replicas != 1 {
+				t.Errorf("default mode must be replicated with 1 replica: %+v", mode)
+			}
+			if service.EndpointSpec.Mode != "" {
+				t.Errorf("default endpoint mode must be empty: %s", service.EndpointSpec.Mode)
+			}
+		case "app_scaled-down":
+			if mode.Replicated == nil || *mode.Replicated.Replicas != 0 {
+				t.Errorf("replicas 0 must be kept: %+v", mode)
+			}
+		case "app_agent":
+			if mode.Global == nil || service.EndpointSpec.Mode != swarm.ResolutionModeDNSRR {
+				t.Errorf("unexpected global service: %+v %+v", mode, service.EndpointSpec)
+			}
+		case "app_migrate":
+			if mode.ReplicatedJob == nil || *mode.ReplicatedJob.TotalCompletions != 3 || *mode.ReplicatedJob.MaxConcurrent != 1 {
+				t.Errorf("unexpected replicated job: %+v", mode.ReplicatedJob)
+			}
+		case "app_prune":
+			if mode.GlobalJob == nil {
+				t.Errorf("unexpected global job: %+v", mode)
+			}
+		}
+	}
+}
+
+func TestInvalidServiceModes(t *testing.T) {
+	invalid := map[string]Deploy{
+		"unknown mode":            {Mode: "daemon"},
+		"global with replicas":    {Mode: ModeGlobal, Replicas: ptr(uint64(2))},
+		"global job with replica": {Mode: ModeGlobalJob, Replicas: ptr(uint64(2))},
+		"job with update config":  {Mode: ModeReplicatedJob, UpdateConfig: &UpdateConfig{}},
+	}
+
+	for name, deploy := range invalid {
+		if _, err := deploy.getServiceMode(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := (&Deploy{EndpointMode: "round-robin"}).getEndpointSpec(nil); err == nil {
+		t.Error("expected error for invalid endpoint_mode")
+	}
+
+	ingress := []swarm.PortConfig{{TargetPort: 80, PublishedPort: 8080}}
+	if _, err := (&Deploy{EndpointMode: "dnsrr"}).getEndpointSpec(ingress); err == nil {
+		t.Error("expected error for ingress port with dnsrr endpoint_mode")
+	}
+
+	host := []swarm.PortConfig{{TargetPort: 80, PublishedPort: 8080, PublishMode: swarm.PortConfigPublishModeHost}}
+	if _, err := (&Deploy{EndpointMode: "dnsrr"}).getEndpointSpec(host); err != nil {
+		t.Errorf("host port must be allowed with dnsrr endpoint_mode: %s", err.Error())
+	}
+}
+
+func TestStandaloneRejectsJobs(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{"migrate": {Image: "app", Deploy: Deploy{Mode: ModeReplicatedJob}}},
+	}
+
+	if _, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "net"}); err == nil {
+		t.Error("expected error for job on standalone docker")
+	}
+}
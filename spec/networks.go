@@ -0,0 +1,236 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// DefaultNetwork is used by services which do not specify networks
+const DefaultNetwork = "default"
+
+type Network struct {
+	Name       string            `yaml:"name"` // defaults to "<app>_<key>", or the key for external networks
+	Driver     string            `yaml:"driver"`
+	DriverOpts map[string]string `yaml:"driver_opts"`
+	Ipam       *Ipam             `yaml:"ipam"`
+	Attachable bool              `yaml:"attachable"`
+	Internal   bool              `yaml:"internal"`
+	Encrypted  bool              `yaml:"encrypted"` // same as driver_opts "encrypted", only for overlay
+	External   bool              `yaml:"external"`
+	Labels     Mapping           `yaml:"labels"`
+}
+
+type Ipam struct {
+	Driver  string            `yaml:"driver"`
+	Config  []IpamConfig      `yaml:"config"`
+	Options map[string]string `yaml:"options"`
+}
+
+type IpamConfig struct {
+	Subnet       string            `yaml:"subnet"`
+	IPRange      string            `yaml:"ip_range"`
+	Gateway      string            `yaml:"gateway"`
+	AuxAddresses map[string]string `yaml:"aux_addresses"`
+}
+
+// ServiceNetworks are the networks of service, in list syntax
+// or map syntax with aliases of the service in that network.
+type ServiceNetworks map[string]*ServiceNetwork
+
+type ServiceNetwork struct {
+	Aliases []string `yaml:"aliases"`
+}
+
+func (n *ServiceNetworks) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		networks := make(ServiceNetworks, len(list))
+		for _, name := range list {
+			networks[name] = nil
+		}
+
+		*n = networks
+		return nil
+	}
+
+	var m map[string]*ServiceNetwork
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+
+	*n = m
+	return nil
+}
+
+// NetworkSpec is a network used by the application,
+// external networks must already exist and are not created.
+type NetworkSpec struct {
+	Key      string // name in service file
+	Name     string
+	External bool
+	Options  types.NetworkCreate
+}
+
+// GetNetworkSpecs returns the networks used by services, sorted by key.
+// driver is the default driver, "overlay" for swarm and "bridge" for standalone docker.
+func (d *DockerSwarm) GetNetworkSpecs(appName string, driver string) ([]NetworkSpec, error) {
+	used := make(map[string]bool)
+	for serviceName, svc := range d.Services {
+		if !d.isEnabled(svc) {
+			continue
+		}
+
+		for _, key := range svc.networkKeys() {
+			if _, found := d.Networks[key]; !found && key != DefaultNetwork {
+				return nil, serviceError(serviceName, "networks", fmt.Errorf("network %s is not defined in top level networks", key))
+			}
+			used[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(used))
+	for key := range used {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	specs := make([]NetworkSpec, 0, len(keys))
+
+	for _, key := range keys {
+		net := d.Networks[key]
+
+		if net.External {
+			name := net.Name
+			if name == "" {
+				name = key
+			}
+
+			specs = append(specs, NetworkSpec{Key: key, Name: name, External: true})
+			continue
+		}
+
+		name := net.Name
+		if name == "" {
+			name = appName + "_" + key
+		}
+
+		options := types.NetworkCreate{
+			Driver:     net.Driver,
+			Scope:      "swarm",
+			Internal:   net.Internal,
+			Attachable: net.Attachable,
+			Labels:     map[string]string{},
+		}
+
+		if options.Driver == "" {
+			options.Driver = driver
+		}
+
+		if driver == "bridge" {
+			options.Scope = "local"
+		}
+
+		for k, v := range net.DriverOpts {
+			if options.Options == nil {
+				options.Options = make(map[string]string)
+			}
+			options.Options[k] = v
+		}
+
+		if net.Encrypted {
+			if options.Driver != "overlay" {
+				return nil, &KeyError{Key: "networks." + key, Err: fmt.Errorf("network %s: encrypted is only supported by overlay driver", key)}
+			}
+
+			if options.Options == nil {
+				options.Options = make(map[string]string)
+			}
+			options.Options["encrypted"] = ""
+		}
+
+		if net.Ipam != nil {
+			options.IPAM = &network.IPAM{
+				Driver:  net.Ipam.Driver,
+				Options: net.Ipam.Options,
+			}
+
+			for _, c := range net.Ipam.Config {
+				options.IPAM.Config = append(options.IPAM.Config, network.IPAMConfig{
+					Subnet:     c.Subnet,
+					IPRange:    c.IPRange,
+					Gateway:    c.Gateway,
+					AuxAddress: c.AuxAddresses,
+				})
+			}
+		}
+
+		for k, v := range net.Labels {
+			options.Labels[k] = v
+		}
+		options.Labels["com.docker.stack.namespace"] = appName
+
+		specs = append(specs, NetworkSpec{Key: key, Name: name, Options: options})
+	}
+
+	return specs, nil
+}
+
+// networkKeys returns the sorted networks of service, or the default network
+func (s *Service) networkKeys() []string {
+	if len(s.Networks) == 0 {
+		return []string{DefaultNetwork}
+	}
+
+	keys := make([]string, 0, len(s.Networks))
+	for key := range s.Networks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// getNetworkAttachments attaches service to its networks, networkIDs are
+// the IDs of the application networks keyed by their name in service file.
+func (s *Service) getNetworkAttachments(serviceName string, networkIDs map[string]string) ([]swarm.NetworkAttachmentConfig, error) {
+	var attachments []swarm.NetworkAttachmentConfig
+
+	for _, key := range s.networkKeys() {
+		id, found := networkIDs[key]
+		if !found {
+			return nil, fmt.Errorf("network %s is not created", key)
+		}
+
+		aliases := []string{serviceName}
+		if net := s.Networks[key]; net != nil {
+			aliases = append(aliases, net.Aliases...)
+		}
+
+		attachments = append(attachments, swarm.NetworkAttachmentConfig{
+			Target:  id,
+			Aliases: aliases,
+		})
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const networksFile = `
+services:
+  web:
+    image: nginx
+    networks:
+      frontend:
+        aliases:
+          - www
+      backend:
+  db:
+    image: postgres
+    networks:
+      - backend
+  worker:
+    image: worker
+networks:
+  frontend:
+    external: true
+    name: traefik_public
+  backend:
+    driver: overlay
+    attachable: true
+    internal: true
+    encrypted: true
+    labels:
+      - com.example.tier=backend
+    ipam:
+      config:
+        - subnet: 10.10.0.0/24
+          gateway: 10.10.0.1
+  unused:
+    driver: overlay
+`
+
+func TestGetNetworkSpecs(t *testing.T) {
+	var d DockerSwarm
+	if err := yaml.Unmarshal([]byte(networksFile), &d); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	networks, err := d.GetNetworkSpecs("app", "overlay")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	keys := []string{}
+	for _, n := range networks {
+		keys = append(keys, n.Key)
+	}
+
+	if !reflect.DeepEqual(keys, []string{"backend", "default", "frontend"}) {
+		t.Fatalf("only used networks must be created, got %v", keys)
+	}
+
+	backend := networks[0]
+	if backend.Name != "app_backend" || backend.External {
+		t.Errorf("unexpected backend network: %+v", backend)
+	}
+
+	options := backend.Options
+	if !options.Attachable || !options.Internal || options.Scope != "swarm" {
+		t.Errorf("unexpected backend options: %+v", options)
+	}
+
+	if _, found := options.Options["encrypted"]; !found {
+		t.Errorf("encrypted network must have encrypted driver option: %v", options.Options)
+	}
+
+	if options.IPAM == nil || options.IPAM.Config[0].Subnet != "10.10.0.0/24" || options.IPAM.Config[0].Gateway != "10.10.0.1" {
+		t.Errorf("unexpected ipam: %+v", options.IPAM)
+	}
+
+	if options.Labels["com.example.tier"] != "backend" || options.Labels["com.docker.stack.namespace"] != "app" {
+		t.Errorf("unexpected labels: %v", options.Labels)
+	}
+
+	if networks[1].Name != "app_default" || networks[1].Options.Driver != "overlay" {
+		t.Errorf("unexpected default network: %+v", networks[1])
+	}
+
+	if !networks[2].External || networks[2].Name != "traefik_public" {
+		t.Errorf("unexpected external network: %+v", networks[2])
+	}
+}
+
+func TestServiceNetworkAttachments(t *testing.T) {
+	var d DockerSwarm
+	if err := yaml.Unmarshal([]byte(networksFile), &d); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ids := map[string]string{"frontend": "f", "backend": "b", DefaultNetwork: "d"}
+
+	services, err := d.GetServiceSpec("app", ids, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, service := range services {
+		networks := service.TaskTemplate.Networks
+
+		switch service.Name {
+		case "app_web":
+			if len(networks) != 2 || networks[0].Target != "b" || networks[1].Target != "f" {
+				t.Errorf("unexpected web networks: %+v", networks)
+			}
+			if !reflect.DeepEqual(networks[1].Aliases, []string{"web", "www"}) {
+				t.Errorf("unexpected web aliases: %v", networks[1].Aliases)
+			}
+		case "app_db":
+			if len(networks) != 1 || networks[0].Target != "b" {
+				t.Errorf("unexpected db networks: %+v", networks)
+			}
+		case "app_worker":
+			if len(networks) != 1 || networks[0].Target != "d" {
+				t.Errorf("worker must use default network: %+v", networks)
+			}
+		}
+	}
+}
+
+func TestUndefinedNetwork(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {Image: "nginx", Networks: ServiceNetworks{"missing": nil}},
+		},
+	}
+
+	if _, err := d.GetNetworkSpecs("app", "overlay"); err == nil {
+		t.Error("expected error for network not defined in top level networks")
+	}
+}
+
+func TestEncryptedBridgeNetwork(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{"web": {Image: "nginx"}},
+		Networks: map[string]Network{DefaultNetwork: {Encrypted: true}},
+	}
+
+	if _, err := d.GetNetworkSpecs("app", "bridge"); err == nil {
+		t.Error("expected error for encrypted bridge network")
+	}
+}
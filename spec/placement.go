@@ -0,0 +1,67 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Placement is the deploy.placement of service
+type Placement struct {
+	Constraints        []string              `yaml:"constraints"` // like "node.labels.db==true"
+	Preferences        []PlacementPreference `yaml:"preferences"`
+	MaxReplicasPerNode uint64                `yaml:"max_replicas_per_node"`
+}
+
+type PlacementPreference struct {
+	Spread string `yaml:"spread"` // like "node.labels.zone"
+}
+
+// getPlacement returns nil when no placement is specified
+func (p *Placement) getPlacement() (*swarm.Placement, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	placement := &swarm.Placement{
+		Constraints: p.Constraints,
+		MaxReplicas: p.MaxReplicasPerNode,
+	}
+
+	for _, constraint := range p.Constraints {
+		if !strings.Contains(constraint, "==") && !strings.Contains(constraint, "!=") {
+			return nil, fmt.Errorf("invalid placement constraint %q, must be like \"node.role==manager\"", constraint)
+		}
+	}
+
+	for _, pref := range p.Preferences {
+		if pref.Spread == "" {
+			return nil, fmt.Errorf("placement preference must have spread")
+		}
+
+		placement.Preferences = append(placement.Preferences, swarm.PlacementPreference{
+			Spread: &swarm.SpreadOver{
+				SpreadDescriptor: pref.Spread,
+			},
+		})
+	}
+
+	return placement, nil
+}
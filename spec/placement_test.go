@@ -0,0 +1,58 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestPlacement(t *testing.T) {
+	var deploy Deploy
+
+	err := yaml.Unmarshal([]byte(`
+placement:
+  constraints:
+    - node.labels.db == true
+    - node.role!=manager
+  preferences:
+    - spread: node.labels.zone
+  max_replicas_per_node: 1
+`), &deploy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	placement, err := deploy.Placement.getPlacement()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(placement.Constraints) != 2 || placement.MaxReplicas != 1 {
+		t.Error("wrong placement", placement)
+	}
+
+	if len(placement.Preferences) != 1 || placement.Preferences[0].Spread.SpreadDescriptor != "node.labels.zone" {
+		t.Error("wrong placement preferences", placement.Preferences)
+	}
+
+	invalid := Placement{Constraints: []string{"node.role"}}
+	if _, err := invalid.getPlacement(); err == nil {
+		t.Error("invalid constraint is not rejected")
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Port is a service port, in short syntax like "127.0.0.1:8080:80/udp"
+// or long syntax with target, published, host_ip, protocol and mode.
+// Target and Published can be range of ports like "8000-8010".
+type Port struct {
+	Target    string `yaml:"target"`
+	Published string `yaml:"published"`
+	HostIP    string `yaml:"host_ip"`
+	Protocol  string `yaml:"protocol"`
+	Mode      string `yaml:"mode"`
+}
+
+func (p *Port) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		port, err := parseShortPort(short)
+		if err != nil {
+			return err
+		}
+
+		*p = port
+		return nil
+	}
+
+	type long Port
+	var l long
+	if err := unmarshal(&l); err != nil {
+		return err
+	}
+
+	*p = Port(l)
+	return nil
+}
+
+// parseShortPort parses [[HOST_IP:]PUBLISHED:]TARGET[/PROTOCOL]
+func parseShortPort(port string) (Port, error) {
+	var p Port
+
+	rest, protocol, found := strings.Cut(port, "/")
+	if found {
+		p.Protocol = protocol
+	}
+
+	// host ip can be ipv6 so using the last ":"
+	index := strings.LastIndex(rest, ":")
+	if index == -1 {
+		p.Target = rest
+		return p, nil
+	}
+
+	p.Target = rest[index+1:]
+	rest = rest[:index]
+
+	index = strings.LastIndex(rest, ":")
+	if index == -1 {
+		p.Published = rest
+		return p, nil
+	}
+
+	p.Published = rest[index+1:]
+	p.HostIP = strings.Trim(rest[:index], "[]")
+
+	if p.HostIP == "" {
+		return Port{}, fmt.Errorf("invalid port %q: empty host ip", port)
+	}
+
+	return p, nil
+}
+
+// portMapping is the swarm port config with the host ip,
+// host ip is not supported by swarm, only by standalone docker.
+type portMapping struct {
+	swarm.PortConfig
+	HostIP string
+}
+
+func (p *Port) getPortMappings() ([]portMapping, error) {
+	protocol := swarm.PortConfigProtocolTCP
+	switch p.Protocol {
+	case "", "tcp":
+	case "udp":
+		protocol = swarm.PortConfigProtocolUDP
+	case "sctp":
+		protocol = swarm.PortConfigProtocolSCTP
+	default:
+		return nil, fmt.Errorf("invalid port protocol: %s", p.Protocol)
+	}
+
+	mode := swarm.PortConfigPublishModeIngress
+	switch p.Mode {
+	case "", "ingress":
+	case "host":
+		mode = swarm.PortConfigPublishModeHost
+	default:
+		return nil, fmt.Errorf("invalid port mode: %s", p.Mode)
+	}
+
+	if p.Target == "" {
+		return nil, fmt.Errorf("port target is missing")
+	}
+
+	targetStart, targetEnd, err := parsePortRange(p.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	publishedStart, publishedEnd := uint32(0), uint32(0)
+	if p.Published != "" {
+		publishedStart, publishedEnd, err = parsePortRange(p.Published)
+		if err != nil {
+			return nil, err
+		}
+
+		if publishedEnd-publishedStart != targetEnd-targetStart {
+			return nil, fmt.Errorf("published port range %s does not match target port range %s", p.Published, p.Target)
+		}
+	}
+
+	mappings := make([]portMapping, 0, targetEnd-targetStart+1)
+
+	for i := uint32(0); i <= targetEnd-targetStart; i++ {
+		published := uint32(0)
+		if publishedStart != 0 {
+			published = publishedStart + i
+		}
+
+		mappings = append(mappings, portMapping{
+			PortConfig: swarm.PortConfig{
+				Protocol:      protocol,
+				TargetPort:    targetStart + i,
+				PublishedPort: published,
+				PublishMode:   mode,
+			},
+			HostIP: p.HostIP,
+		})
+	}
+
+	return mappings, nil
+}
+
+// parsePortRange parses "80" or range "8000-8010"
+func parsePortRange(ports string) (start uint32, end uint32, err error) {
+	first, last, isRange := strings.Cut(ports, "-")
+
+	start, err = parsePortNumber(first)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !isRange {
+		return start, start, nil
+	}
+
+	end, err = parsePortNumber(last)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid port range: %s", ports)
+	}
+
+	return start, end, nil
+}
+
+func parsePortNumber(port string) (uint32, error) {
+	n, err := strconv.ParseUint(strings.TrimSpace(port), 10, 16)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid port number: %q", port)
+	}
+
+	return uint32(n), nil
+}
+
+func getPortMappings(ports []Port) ([]portMapping, error) {
+	mappings := make([]portMapping, 0, len(ports))
+
+	for _, port := range ports {
+		m, err := port.getPortMappings()
+		if err != nil {
+			return nil, err
+		}
+
+		mappings = append(mappings, m...)
+	}
+
+	return mappings, nil
+}
+
+func getSwarmPorts(serviceName string, ports []Port) ([]swarm.PortConfig, error) {
+	mappings, err := getPortMappings(ports)
+	if err != nil {
+		return nil, serviceError(serviceName, "ports", err)
+	}
+
+	configs := make([]swarm.PortConfig, 0, len(mappings))
+
+	for _, m := range mappings {
+		if m.HostIP != "" {
+			slog.Warn("Ignoring host ip of port, swarm services listen on all interfaces", "service", serviceName, "host_ip", m.HostIP)
+		}
+
+		configs = append(configs, m.PortConfig)
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"gopkg.in/yaml.v2"
+)
+
+func TestPortsSyntax(t *testing.T) {
+	var svc Service
+
+	err := yaml.Unmarshal([]byte(`
+ports:
+  - "3000"
+  - "8080:80/udp"
+  - "127.0.0.1:8081:81"
+  - "[::1]:8082:82"
+  - "9000-9001:90-91"
+  - target: 443
+    published: 8443
+    protocol: tcp
+    mode: host
+`), &svc)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ports, err := getSwarmPorts("svc", svc.Ports)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []swarm.PortConfig{
+		{Protocol: "tcp", TargetPort: 3000, PublishMode: "ingress"},
+		{Protocol: "udp", TargetPort: 80, PublishedPort: 8080, PublishMode: "ingress"},
+		{Protocol: "tcp", TargetPort: 81, PublishedPort: 8081, PublishMode: "ingress"},
+		{Protocol: "tcp", TargetPort: 82, PublishedPort: 8082, PublishMode: "ingress"},
+		{Protocol: "tcp", TargetPort: 90, PublishedPort: 9000, PublishMode: "ingress"},
+		{Protocol: "tcp", TargetPort: 91, PublishedPort: 9001, PublishMode: "ingress"},
+		{Protocol: "tcp", TargetPort: 443, PublishedPort: 8443, PublishMode: "host"},
+	}
+
+	if len(ports) != len(expected) {
+		t.Fatal("wrong number of ports", ports)
+	}
+
+	for i := range expected {
+		if ports[i] != expected[i] {
+			t.Error("wrong port config", "got", ports[i], "expected", expected[i])
+		}
+	}
+
+	if svc.Ports[3].HostIP != "::1" {
+		t.Error("ipv6 host ip is not parsed", svc.Ports[3])
+	}
+}
+
+func TestInvalidPorts(t *testing.T) {
+	testCaseNeg := []string{
+		"http:80",
+		"8080:80/icmp",
+		"70000:80",
+		"9000-9002:90-91",
+		":8080:80",
+	}
+
+	for _, port := range testCaseNeg {
+		var p Port
+		if err := yaml.Unmarshal([]byte(port), &p); err != nil {
+			continue
+		}
+
+		if _, err := getSwarmPorts("svc", []Port{p}); err == nil {
+			t.Error("invalid port is not rejected", port)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+// isEnabled checks if the service is deployed with the active profiles,
+// services without profiles are always enabled and "*" enables all.
+func (d *DockerSwarm) isEnabled(svc Service) bool {
+	if len(svc.Profiles) == 0 {
+		return true
+	}
+
+	for _, active := range d.Profiles {
+		if active == "*" || contains(svc.Profiles, active) {
+			return true
+		}
+	}
+
+	return false
+}
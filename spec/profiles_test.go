@@ -0,0 +1,93 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const profilesFile = `
+services:
+  web:
+    image: app
+  debug:
+    image: debugger
+    profiles: ["debug"]
+    networks:
+      - debug
+  metrics:
+    image: exporter
+    profiles: ["debug", "monitoring"]
+networks:
+  debug:
+`
+
+func TestProfiles(t *testing.T) {
+	tests := map[string]struct {
+		profiles []string
+		services []string
+		networks int
+	}{
+		"no profile":   {nil, []string{"app_web"}, 1},
+		"debug":        {[]string{"debug"}, []string{"app_debug", "app_metrics", "app_web"}, 2},
+		"monitoring":   {[]string{"monitoring"}, []string{"app_metrics", "app_web"}, 1},
+		"all profiles": {[]string{"*"}, []string{"app_debug", "app_metrics", "app_web"}, 2},
+	}
+
+	for name, test := range tests {
+		var d DockerSwarm
+		if err := yaml.Unmarshal([]byte(profilesFile), &d); err != nil {
+			t.Fatal(err.Error())
+		}
+		d.Profiles = test.profiles
+
+		networks, err := d.GetNetworkSpecs("app", "overlay")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if len(networks) != test.networks {
+			t.Errorf("%s: expected %d networks, got %d", name, test.networks, len(networks))
+		}
+
+		services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "d", "debug": "n"}, Objects{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		var names []string
+		for _, service := range services {
+			names = append(names, service.Name)
+		}
+		sort.Strings(names)
+
+		if len(names) != len(test.services) {
+			t.Errorf("%s: expected %v, got %v", name, test.services, names)
+			continue
+		}
+
+		for i := range names {
+			if names[i] != test.services[i] {
+				t.Errorf("%s: expected %v, got %v", name, test.services, names)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-units"
+)
+
+// Resources is the deploy.resources of service
+type Resources struct {
+	Limits       *ResourceLimits       `yaml:"limits"`
+	Reservations *ResourceReservations `yaml:"reservations"`
+}
+
+type ResourceLimits struct {
+	Cpus   string `yaml:"cpus"`   // like "0.5"
+	Memory string `yaml:"memory"` // like "512M" or "1gb"
+	Pids   int64  `yaml:"pids"`
+}
+
+type ResourceReservations struct {
+	Cpus             string            `yaml:"cpus"`
+	Memory           string            `yaml:"memory"`
+	GenericResources []GenericResource `yaml:"generic_resources"`
+}
+
+type GenericResource struct {
+	DiscreteResourceSpec *DiscreteResourceSpec `yaml:"discrete_resource_spec"`
+	NamedResourceSpec    *NamedResourceSpec    `yaml:"named_resource_spec"`
+}
+
+type DiscreteResourceSpec struct {
+	Kind  string `yaml:"kind"`
+	Value int64  `yaml:"value"`
+}
+
+type NamedResourceSpec struct {
+	Kind  string `yaml:"kind"`
+	Value string `yaml:"value"`
+}
+
+// getResourceRequirements returns nil when no resources are specified
+func (r *Resources) getResourceRequirements() (*swarm.ResourceRequirements, error) {
+	if r == nil || (r.Limits == nil && r.Reservations == nil) {
+		return nil, nil
+	}
+
+	requirements := &swarm.ResourceRequirements{}
+
+	if r.Limits != nil {
+		nanoCPUs, err := parseCPUs(r.Limits.Cpus)
+		if err != nil {
+			return nil, fmt.Errorf("resources limits: %w", err)
+		}
+
+		memory, err := parseMemory(r.Limits.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("resources limits: %w", err)
+		}
+
+		if r.Limits.Pids < 0 {
+			return nil, fmt.Errorf("resources limits: invalid pids %d", r.Limits.Pids)
+		}
+
+		requirements.Limits = &swarm.Limit{
+			NanoCPUs:    nanoCPUs,
+			MemoryBytes: memory,
+			Pids:        r.Limits.Pids,
+		}
+	}
+
+	if r.Reservations != nil {
+		nanoCPUs, err := parseCPUs(r.Reservations.Cpus)
+		if err != nil {
+			return nil, fmt.Errorf("resources reservations: %w", err)
+		}
+
+		memory, err := parseMemory(r.Reservations.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("resources reservations: %w", err)
+		}
+
+		requirements.Reservations = &swarm.Resources{
+			NanoCPUs:    nanoCPUs,
+			MemoryBytes: memory,
+		}
+
+		for _, res := range r.Reservations.GenericResources {
+			switch {
+			case res.DiscreteResourceSpec != nil:
+				requirements.Reservations.GenericResources = append(requirements.Reservations.GenericResources, swarm.GenericResource{
+					DiscreteResourceSpec: &swarm.DiscreteGenericResource{
+						Kind:  res.DiscreteResourceSpec.Kind,
+						Value: res.DiscreteResourceSpec.Value,
+					},
+				})
+			case res.NamedResourceSpec != nil:
+				requirements.Reservations.GenericResources = append(requirements.Reservations.GenericResources, swarm.GenericResource{
+					NamedResourceSpec: &swarm.NamedGenericResource{
+						Kind:  res.NamedResourceSpec.Kind,
+						Value: res.NamedResourceSpec.Value,
+					},
+				})
+			default:
+				return nil, fmt.Errorf("resources reservations: generic resource must have discrete_resource_spec or named_resource_spec")
+			}
+		}
+	}
+
+	return requirements, nil
+}
+
+// parseCPUs converts cpus like "1.5" into nano cpus
+func parseCPUs(cpus string) (int64, error) {
+	if cpus == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseFloat(cpus, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid cpus %q", cpus)
+	}
+
+	return int64(n * 1e9), nil
+}
+
+// parseMemory converts memory like "512M", "1.5gb" or bytes into bytes
+func parseMemory(memory string) (int64, error) {
+	if memory == "" {
+		return 0, nil
+	}
+
+	bytes, err := units.RAMInBytes(memory)
+	if err != nil || bytes < 0 {
+		return 0, fmt.Errorf("invalid memory %q", memory)
+	}
+
+	return bytes, nil
+}
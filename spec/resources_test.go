@@ -0,0 +1,77 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestResources(t *testing.T) {
+	var deploy Deploy
+
+	err := yaml.Unmarshal([]byte(`
+resources:
+  limits:
+    cpus: '0.50'
+    memory: 50M
+    pids: 100
+  reservations:
+    cpus: 0.25
+    memory: 1gb
+    generic_resources:
+      - discrete_resource_spec:
+          kind: gpu
+          value: 2
+`), &deploy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	res, err := deploy.Resources.getResourceRequirements()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if res.Limits.NanoCPUs != 500000000 || res.Limits.MemoryBytes != 50*1024*1024 || res.Limits.Pids != 100 {
+		t.Error("wrong resource limits", res.Limits)
+	}
+
+	if res.Reservations.NanoCPUs != 250000000 || res.Reservations.MemoryBytes != 1024*1024*1024 {
+		t.Error("wrong resource reservations", res.Reservations)
+	}
+
+	if len(res.Reservations.GenericResources) != 1 ||
+		res.Reservations.GenericResources[0].DiscreteResourceSpec.Kind != "gpu" ||
+		res.Reservations.GenericResources[0].DiscreteResourceSpec.Value != 2 {
+		t.Error("wrong generic resources", res.Reservations.GenericResources)
+	}
+
+	testCaseNeg := []Resources{
+		{Limits: &ResourceLimits{Cpus: "half"}},
+		{Limits: &ResourceLimits{Memory: "50 potatoes"}},
+		{Reservations: &ResourceReservations{Cpus: "-1"}},
+		{Reservations: &ResourceReservations{GenericResources: []GenericResource{{}}}},
+	}
+
+	for _, r := range testCaseNeg {
+		if _, err := r.getResourceRequirements(); err == nil {
+			t.Error("invalid resources are not rejected", r)
+		}
+	}
+}
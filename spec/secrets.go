@@ -0,0 +1,160 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Object is a top level secret or config of the service file,
+// its content is read from File in the repository or it is External
+// and must already exist in the swarm.
+type Object struct {
+	Name     string  `yaml:"name"` // name of the external object, defaults to the key
+	File     string  `yaml:"file"`
+	External bool    `yaml:"external"`
+	Labels   Mapping `yaml:"labels"`
+}
+
+// ServiceObject is a secret or config used by a service, in short syntax
+// like "db_password" or long syntax with source, target, uid, gid and mode.
+type ServiceObject struct {
+	Source string  `yaml:"source"`
+	Target string  `yaml:"target"`
+	UID    string  `yaml:"uid"`
+	GID    string  `yaml:"gid"`
+	Mode   *uint32 `yaml:"mode"` // like 0440
+}
+
+func (o *ServiceObject) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		*o = ServiceObject{Source: short}
+		return nil
+	}
+
+	type long ServiceObject
+	var l long
+	if err := unmarshal(&l); err != nil {
+		return err
+	}
+
+	*o = ServiceObject(l)
+	return nil
+}
+
+// ObjectRef is a swarm secret or config created (or found) for the application
+type ObjectRef struct {
+	ID   string
+	Name string
+}
+
+// Objects are the swarm secrets and configs of the application,
+// keyed with their name in the service file.
+type Objects struct {
+	Secrets map[string]ObjectRef
+	Configs map[string]ObjectRef
+}
+
+const defaultObjectMode = 0444
+
+func (o ServiceObject) fileTarget(defaultTarget string) (name, uid, gid string, mode os.FileMode) {
+	name = o.Target
+	if name == "" {
+		name = defaultTarget
+	}
+
+	uid, gid = o.UID, o.GID
+	if uid == "" {
+		uid = "0"
+	}
+	if gid == "" {
+		gid = "0"
+	}
+
+	mode = defaultObjectMode
+	if o.Mode != nil {
+		mode = os.FileMode(*o.Mode)
+	}
+
+	return name, uid, gid, mode
+}
+
+// getSecretReferences returns the secrets of service, mounted at /run/secrets/<target>
+func getSecretReferences(secrets []ServiceObject, objects map[string]ObjectRef) ([]*swarm.SecretReference, error) {
+	var refs []*swarm.SecretReference
+
+	for _, s := range secrets {
+		if s.Source == "" {
+			return nil, fmt.Errorf("secret must have source")
+		}
+
+		obj, found := objects[s.Source]
+		if !found {
+			return nil, fmt.Errorf("secret %s is not defined in top level secrets", s.Source)
+		}
+
+		name, uid, gid, mode := s.fileTarget(s.Source)
+
+		refs = append(refs, &swarm.SecretReference{
+			File: &swarm.SecretReferenceFileTarget{
+				Name: name,
+				UID:  uid,
+				GID:  gid,
+				Mode: mode,
+			},
+			SecretID:   obj.ID,
+			SecretName: obj.Name,
+		})
+	}
+
+	return refs, nil
+}
+
+// getConfigReferences returns the configs of service, mounted at /<source> by default
+func getConfigReferences(configs []ServiceObject, objects map[string]ObjectRef) ([]*swarm.ConfigReference, error) {
+	var refs []*swarm.ConfigReference
+
+	for _, c := range configs {
+		if c.Source == "" {
+			return nil, fmt.Errorf("config must have source")
+		}
+
+		obj, found := objects[c.Source]
+		if !found {
+			return nil, fmt.Errorf("config %s is not defined in top level configs", c.Source)
+		}
+
+		name, uid, gid, mode := c.fileTarget("/" + c.Source)
+
+		refs = append(refs, &swarm.ConfigReference{
+			File: &swarm.ConfigReferenceFileTarget{
+				Name: name,
+				UID:  uid,
+				GID:  gid,
+				Mode: mode,
+			},
+			ConfigID:   obj.ID,
+			ConfigName: obj.Name,
+		})
+	}
+
+	return refs, nil
+}
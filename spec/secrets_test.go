@@ -0,0 +1,97 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestServiceObjectSyntax(t *testing.T) {
+	var svc Service
+	err := yaml.Unmarshal([]byte(`
+secrets:
+  - db_password
+  - source: api_key
+    target: key.txt
+    uid: "1000"
+    mode: 0400
+configs:
+  - nginx_conf
+`), &svc)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	objects := map[string]ObjectRef{
+		"db_password": {ID: "s1", Name: "app_db_password-abc"},
+		"api_key":     {ID: "s2", Name: "app_api_key-def"},
+	}
+
+	secrets, err := getSecretReferences(svc.Secrets, objects)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(secrets) != 2 {
+		t.Fatalf("expected 2 secrets, got %d", len(secrets))
+	}
+
+	if secrets[0].File.Name != "db_password" || secrets[0].File.Mode != 0444 || secrets[0].File.UID != "0" {
+		t.Errorf("unexpected defaults for short syntax: %+v", secrets[0].File)
+	}
+
+	if secrets[0].SecretID != "s1" || secrets[0].SecretName != "app_db_password-abc" {
+		t.Errorf("secret is not referenced by id and name: %+v", secrets[0])
+	}
+
+	if secrets[1].File.Name != "key.txt" || secrets[1].File.Mode != 0400 || secrets[1].File.UID != "1000" || secrets[1].File.GID != "0" {
+		t.Errorf("unexpected long syntax: %+v", secrets[1].File)
+	}
+
+	configs, err := getConfigReferences(svc.Configs, map[string]ObjectRef{"nginx_conf": {ID: "c1", Name: "app_nginx_conf-123"}})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if configs[0].File.Name != "/nginx_conf" || configs[0].ConfigID != "c1" {
+		t.Errorf("unexpected config reference: %+v", configs[0])
+	}
+}
+
+func TestUndefinedServiceObject(t *testing.T) {
+	if _, err := getSecretReferences([]ServiceObject{{Source: "missing"}}, map[string]ObjectRef{}); err == nil {
+		t.Error("expected error for secret not defined in top level secrets")
+	}
+
+	if _, err := getConfigReferences([]ServiceObject{{Source: "missing"}}, nil); err == nil {
+		t.Error("expected error for config not defined in top level configs")
+	}
+}
+
+func TestStandaloneRejectsSecrets(t *testing.T) {
+	d := DockerSwarm{
+		Services: map[string]Service{
+			"web": {Image: "nginx", Secrets: []ServiceObject{{Source: "db_password"}}},
+		},
+	}
+
+	if _, err := d.GetContainerSpec("app", map[string]string{DefaultNetwork: "net"}); err == nil {
+		t.Error("expected error for secrets on standalone docker")
+	}
+}
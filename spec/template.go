@@ -0,0 +1,86 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateSuffix marks the service files which are go templates, like "compose.yaml.tmpl"
+const TemplateSuffix = ".tmpl"
+
+// IsTemplate reports if the service file is rendered as go template before it is parsed
+func IsTemplate(file string) bool {
+	return strings.HasSuffix(file, TemplateSuffix)
+}
+
+// RenderTemplate renders the service file with values like "{{ .domain }}",
+// values missing in the template are an error.
+func RenderTemplate(name, content string, values map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, values); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// ParseValues parses the values file like "values.yaml" for templates
+func ParseValues(content []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// SetValue sets the parameter in values, the key like "ingress.domain" sets the nested value
+func SetValue(values map[string]interface{}, key, value string) error {
+	parts := strings.Split(key, ".")
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("invalid parameter %q", key)
+		}
+	}
+
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := values[part].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			values[part] = nested
+		}
+		values = nested
+	}
+
+	values[parts[len(parts)-1]] = value
+	return nil
+}
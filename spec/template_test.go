@@ -0,0 +1,102 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	values, err := ParseValues([]byte("replicas: 1\ningress:\n  domain: example.com\n  tls: true\n"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := SetValue(values, "ingress.domain", "customer.com"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := SetValue(values, "db.host", "db"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	ingress := values["ingress"].(map[string]interface{})
+	if ingress["domain"] != "customer.com" || ingress["tls"] != true {
+		t.Error("nested parameter is not set over values", values)
+	}
+
+	if values["db"].(map[string]interface{})["host"] != "db" {
+		t.Error("nested parameter is not created", values)
+	}
+
+	if err := SetValue(values, "ingress.", "x"); err == nil {
+		t.Error("invalid parameter is not rejected")
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	files := mapFiles{
+		"compose.yaml.tmpl": `
+services:
+  web:
+    image: app:${TAG:-latest}
+    labels:
+      traefik.host: "{{ .ingress.domain }}"
+    deploy:
+      replicas: {{ .replicas }}
+{{- if index . "debug" }}
+    environment:
+      DEBUG: "1"
+{{- end }}
+`,
+	}
+
+	loader := Loader{
+		Files:  files,
+		Lookup: func(string) (string, bool) { return "", false },
+		Values: map[string]interface{}{
+			"replicas": "3",
+			"ingress":  map[string]interface{}{"domain": "customer.com"},
+		},
+	}
+
+	d, err := loader.Load([]string{"compose.yaml.tmpl"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	web := d.Services["web"]
+	if web.Image != "app:latest" || web.Labels["traefik.host"] != "customer.com" {
+		t.Error("template is not rendered before interpolation", web)
+	}
+
+	if web.Deploy.Replicas == nil || *web.Deploy.Replicas != 3 || len(web.Environment) != 0 {
+		t.Error("template values are not used", web.Deploy, web.Environment)
+	}
+
+	loader.Values = nil
+	if _, err := loader.Load([]string{"compose.yaml.tmpl"}); err == nil || !strings.Contains(err.Error(), "compose.yaml.tmpl") {
+		t.Error("missing template value is not an error", err)
+	}
+
+	// files without the template suffix are not rendered
+	files["compose.yaml"] = "services:\n  web:\n    image: app\n    command: echo '{{ .x }}'\n"
+	if _, err := loader.Load([]string{"compose.yaml"}); err != nil {
+		t.Error(err.Error())
+	}
+}
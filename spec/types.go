@@ -0,0 +1,160 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// parseDuration parses the compose duration like "1m30s",
+// empty duration is zero.
+func parseDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s duration %q: %w", field, value, err)
+	}
+
+	return d, nil
+}
+
+// Mapping is a compose mapping like labels or sysctls, in map syntax
+// or list syntax like "key=value", keys without value are empty.
+type Mapping map[string]string
+
+func (m *Mapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var mapping map[string]string
+	if err := unmarshal(&mapping); err == nil {
+		*m = mapping
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	result := make(Mapping, len(list))
+	for _, item := range list {
+		key, value, _ := strings.Cut(item, "=")
+		if key == "" {
+			return fmt.Errorf("invalid mapping %q, must be like key=value", item)
+		}
+		result[key] = value
+	}
+
+	*m = result
+	return nil
+}
+
+// MappingWithEquals is the compose environment, in map syntax or list syntax
+// like "KEY=value". Keys without value like "KEY" or "KEY:" are nil, their
+// value is taken from the variables of application.
+type MappingWithEquals map[string]*string
+
+func (m *MappingWithEquals) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var mapping map[string]*string
+	if err := unmarshal(&mapping); err == nil {
+		*m = mapping
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	result := make(MappingWithEquals, len(list))
+	for _, item := range list {
+		key, value, found := strings.Cut(item, "=")
+		if key == "" {
+			return fmt.Errorf("invalid variable %q, must be like KEY=value", item)
+		}
+
+		if !found {
+			result[key] = nil
+			continue
+		}
+
+		result[key] = &value
+	}
+
+	*m = result
+	return nil
+}
+
+// ExtraHosts are the extra_hosts of service, in map syntax or list
+// syntax like "host:ip" or "host=ip", they are kept like "host:ip".
+type ExtraHosts []string
+
+func (h *ExtraHosts) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var mapping map[string]string
+	if err := unmarshal(&mapping); err == nil {
+		hosts := make(ExtraHosts, 0, len(mapping))
+		for host, ip := range mapping {
+			hosts = append(hosts, host+":"+ip)
+		}
+		sort.Strings(hosts)
+
+		*h = hosts
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	hosts := make(ExtraHosts, 0, len(list))
+	for _, item := range list {
+		// host names can not have ":" or "=", but ipv6 address can have ":"
+		index := strings.IndexAny(item, ":=")
+		if index <= 0 || index == len(item)-1 {
+			return fmt.Errorf("invalid extra host %q, must be like host:ip", item)
+		}
+
+		hosts = append(hosts, item[:index]+":"+item[index+1:])
+	}
+
+	*h = hosts
+	return nil
+}
+
+// StringList is a list of strings, also accepting a single string like "app.env"
+type StringList []string
+
+func (s *StringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*s = StringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	*s = list
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestServiceLabels(t *testing.T) {
+	var d DockerSwarm
+	err := yaml.Unmarshal([]byte(`
+services:
+  web:
+    image: nginx
+    labels:
+      - com.example.role=frontend
+      - com.example.empty
+      - com.docker.stack.namespace=other
+    deploy:
+      labels:
+        traefik.enable: true
+        traefik.http.services.web.loadbalancer.server.port: 80
+        com.docker.stack.namespace: other
+`), &d)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	service := services[0]
+
+	if service.Labels["traefik.enable"] != "true" || service.Labels["traefik.http.services.web.loadbalancer.server.port"] != "80" {
+		t.Errorf("deploy labels are not in service labels: %v", service.Labels)
+	}
+
+	if service.Labels["com.docker.stack.namespace"] != "app" || service.Labels["com.docker.stack.image"] != "nginx" {
+		t.Errorf("namespace labels are overridden: %v", service.Labels)
+	}
+
+	containerLabels := service.TaskTemplate.ContainerSpec.Labels
+
+	if containerLabels["com.example.role"] != "frontend" {
+		t.Errorf("labels are not in container labels: %v", containerLabels)
+	}
+
+	if value, found := containerLabels["com.example.empty"]; !found || value != "" {
+		t.Errorf("label without value is not empty: %v", containerLabels)
+	}
+
+	if containerLabels["com.docker.stack.namespace"] != "app" {
+		t.Errorf("namespace label is overridden: %v", containerLabels)
+	}
+
+	if _, found := containerLabels["traefik.enable"]; found {
+		t.Errorf("deploy labels must not be in container labels: %v", containerLabels)
+	}
+}
+
+func TestInvalidLabel(t *testing.T) {
+	var l Mapping
+	if err := yaml.Unmarshal([]byte(`["=value"]`), &l); err == nil {
+		t.Error("expected error for label without key")
+	}
+}
+
+func envValue(env MappingWithEquals, key string) string {
+	if value := env[key]; value != nil {
+		return *value
+	}
+	return ""
+}
+
+func TestEnvironmentSyntax(t *testing.T) {
+	var d DockerSwarm
+	err := yaml.Unmarshal([]byte(`
+services:
+  list:
+    image: app
+    env_file: app.env
+    environment:
+      - DEBUG=true
+      - EMPTY=
+      - FROM_VARS
+      - NOT_SET
+      - PORT=9090
+  map:
+    image: app
+    environment:
+      REPLICAS: 3
+      ENABLED: true
+      RATIO: 0.5
+      FROM_VARS:
+`), &d)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	d.Files = mapFiles{"app.env": "PORT=8080\nHOST=web\n"}
+	d.Lookup = func(name string) (string, bool) {
+		return "from-vars", name == "FROM_VARS"
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := map[string][]string{
+		"app_list": {"DEBUG=true", "EMPTY=", "FROM_VARS=from-vars", "HOST=web", "PORT=9090"},
+		"app_map":  {"ENABLED=true", "FROM_VARS=from-vars", "RATIO=0.5", "REPLICAS=3"},
+	}
+
+	for _, service := range services {
+		env := service.TaskTemplate.ContainerSpec.Env
+		if !reflect.DeepEqual(env, expected[service.Name]) {
+			t.Errorf("%s: expected %v, got %v", service.Name, expected[service.Name], env)
+		}
+	}
+}
+
+func TestMappingSyntax(t *testing.T) {
+	var s struct {
+		Map  Mapping `yaml:"map"`
+		List Mapping `yaml:"list"`
+	}
+
+	err := yaml.Unmarshal([]byte(`
+map:
+  net.core.somaxconn: 1024
+  enabled: true
+list:
+  - net.ipv4.tcp_syncookies=0
+  - empty
+`), &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.Map["net.core.somaxconn"] != "1024" || s.Map["enabled"] != "true" {
+		t.Errorf("unexpected map syntax: %v", s.Map)
+	}
+
+	if s.List["net.ipv4.tcp_syncookies"] != "0" || s.List["empty"] != "" {
+		t.Errorf("unexpected list syntax: %v", s.List)
+	}
+}
+
+func TestExtraHostsSyntax(t *testing.T) {
+	var s struct {
+		Map  ExtraHosts `yaml:"map"`
+		List ExtraHosts `yaml:"list"`
+	}
+
+	err := yaml.Unmarshal([]byte(`
+map:
+  somehost: 162.242.195.82
+  otherhost: 50.31.209.229
+list:
+  - somehost:162.242.195.82
+  - otherhost=50.31.209.229
+  - myhostv6:::1
+`), &s)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !reflect.DeepEqual([]string(s.Map), []string{"otherhost:50.31.209.229", "somehost:162.242.195.82"}) {
+		t.Errorf("unexpected map syntax: %v", s.Map)
+	}
+
+	if !reflect.DeepEqual([]string(s.List), []string{"somehost:162.242.195.82", "otherhost:50.31.209.229", "myhostv6:::1"}) {
+		t.Errorf("unexpected list syntax: %v", s.List)
+	}
+
+	var invalid ExtraHosts
+	if err := yaml.Unmarshal([]byte(`["somehost"]`), &invalid); err == nil {
+		t.Error("expected error for extra host without ip")
+	}
+}
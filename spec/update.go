@@ -0,0 +1,131 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// UpdateConfig is the deploy.update_config and deploy.rollback_config of service
+type UpdateConfig struct {
+	Parallelism     *uint64 `yaml:"parallelism"`
+	Delay           string  `yaml:"delay"`
+	FailureAction   string  `yaml:"failure_action"`
+	Monitor         string  `yaml:"monitor"`
+	MaxFailureRatio float32 `yaml:"max_failure_ratio"`
+	Order           string  `yaml:"order"`
+}
+
+// RestartPolicy is the deploy.restart_policy of service
+type RestartPolicy struct {
+	Condition   string  `yaml:"condition"`
+	Delay       string  `yaml:"delay"`
+	MaxAttempts *uint64 `yaml:"max_attempts"`
+	Window      string  `yaml:"window"`
+}
+
+// getUpdateConfig returns nil when not specified, so that docker defaults are used.
+// failureActions are the allowed failure_action, rollback can not fail with "rollback"
+func (u *UpdateConfig) getUpdateConfig(field string, failureActions ...string) (*swarm.UpdateConfig, error) {
+	if u == nil {
+		return nil, nil
+	}
+
+	// parallelism is 1 by default, same as docker stack deploy
+	parallelism := uint64(1)
+	if u.Parallelism != nil {
+		parallelism = *u.Parallelism
+	}
+
+	config := &swarm.UpdateConfig{
+		Parallelism:     parallelism,
+		FailureAction:   u.FailureAction,
+		MaxFailureRatio: u.MaxFailureRatio,
+		Order:           u.Order,
+	}
+
+	if u.FailureAction != "" && !contains(failureActions, u.FailureAction) {
+		return nil, fmt.Errorf("invalid %s failure_action %q, must be one of %v", field, u.FailureAction, failureActions)
+	}
+
+	if u.Order != "" && u.Order != swarm.UpdateOrderStopFirst && u.Order != swarm.UpdateOrderStartFirst {
+		return nil, fmt.Errorf("invalid %s order %q, must be %s or %s", field, u.Order, swarm.UpdateOrderStopFirst, swarm.UpdateOrderStartFirst)
+	}
+
+	if u.MaxFailureRatio < 0 || u.MaxFailureRatio > 1 {
+		return nil, fmt.Errorf("invalid %s max_failure_ratio %v, must be between 0 and 1", field, u.MaxFailureRatio)
+	}
+
+	var err error
+
+	if config.Delay, err = parseDuration(field+" delay", u.Delay); err != nil {
+		return nil, err
+	}
+
+	if config.Monitor, err = parseDuration(field+" monitor", u.Monitor); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// getRestartPolicy returns nil when not specified, so that docker default (any) is used
+func (r *RestartPolicy) getRestartPolicy() (*swarm.RestartPolicy, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	policy := &swarm.RestartPolicy{
+		Condition:   swarm.RestartPolicyCondition(r.Condition),
+		MaxAttempts: r.MaxAttempts,
+	}
+
+	switch policy.Condition {
+	case "", swarm.RestartPolicyConditionNone, swarm.RestartPolicyConditionOnFailure, swarm.RestartPolicyConditionAny:
+	default:
+		return nil, fmt.Errorf("invalid restart_policy condition %q, must be none, on-failure or any", r.Condition)
+	}
+
+	if r.Delay != "" {
+		delay, err := parseDuration("restart_policy delay", r.Delay)
+		if err != nil {
+			return nil, err
+		}
+		policy.Delay = &delay
+	}
+
+	if r.Window != "" {
+		window, err := parseDuration("restart_policy window", r.Window)
+		if err != nil {
+			return nil, err
+		}
+		policy.Window = &window
+	}
+
+	return policy, nil
+}
+
+func contains(list []string, item string) bool {
+	for _, x := range list {
+		if x == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+	"gopkg.in/yaml.v2"
+)
+
+func TestUpdateConfig(t *testing.T) {
+	var deploy Deploy
+
+	err := yaml.Unmarshal([]byte(`
+update_config:
+  parallelism: 2
+  delay: 10s
+  failure_action: rollback
+  order: start-first
+rollback_config:
+  monitor: 1m
+restart_policy:
+  condition: on-failure
+  delay: 5s
+  max_attempts: 3
+  window: 120s
+`), &deploy)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	update, err := deploy.UpdateConfig.getUpdateConfig("update_config", swarm.UpdateFailureActionRollback)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if update.Parallelism != 2 || update.Delay != 10*time.Second ||
+		update.FailureAction != "rollback" || update.Order != "start-first" {
+		t.Error("wrong update config", update)
+	}
+
+	rollback, err := deploy.RollbackConfig.getUpdateConfig("rollback_config")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if rollback.Parallelism != 1 || rollback.Monitor != time.Minute {
+		t.Error("wrong rollback config", rollback)
+	}
+
+	restart, err := deploy.RestartPolicy.getRestartPolicy()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if restart.Condition != swarm.RestartPolicyConditionOnFailure || *restart.Delay != 5*time.Second ||
+		*restart.MaxAttempts != 3 || *restart.Window != 2*time.Minute {
+		t.Error("wrong restart policy", restart)
+	}
+
+	invalidRollback := UpdateConfig{FailureAction: "rollback"}
+	if _, err := invalidRollback.getUpdateConfig("rollback_config", swarm.UpdateFailureActionPause); err == nil {
+		t.Error("invalid failure_action is not rejected")
+	}
+
+	invalidRestart := RestartPolicy{Condition: "always"}
+	if _, err := invalidRestart.getRestartPolicy(); err == nil {
+		t.Error("invalid restart condition is not rejected")
+	}
+}
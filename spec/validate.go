@@ -0,0 +1,282 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem in service file at the line of the key or value
+type ValidationError struct {
+	File    string
+	Line    int // 0 when the line is not known
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// ValidationErrors are all the problems found in service file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// unsupportedServiceKeys are the docker compose keys of service which meltcd does not deploy,
+// like "docker stack deploy" they are ignored with a warning
+var unsupportedServiceKeys = map[string]bool{
+	"annotations": true, "attach": true, "cgroup": true, "cgroup_parent": true, "container_name": true,
+	"cpu_count": true, "cpu_percent": true, "cpu_period": true, "cpu_quota": true, "cpu_rt_period": true,
+	"cpu_rt_runtime": true, "cpu_shares": true, "cpus": true, "cpuset": true, "credential_spec": true,
+	"depends_on": true, "develop": true, "device_cgroup_rules": true, "devices": true, "dns": true,
+	"dns_opt": true, "dns_search": true, "domainname": true, "expose": true, "external_links": true,
+	"group_add": true, "ipc": true, "isolation": true, "links": true, "mac_address": true, "mem_limit": true,
+	"mem_reservation": true, "mem_swappiness": true, "memswap_limit": true, "network_mode": true,
+	"oom_kill_disable": true, "oom_score_adj": true, "pid": true, "pids_limit": true, "platform": true,
+	"privileged": true, "pull_policy": true, "runtime": true, "scale": true, "security_opt": true,
+	"shm_size": true, "storage_opt": true, "tmpfs": true, "userns_mode": true, "uts": true, "volumes_from": true,
+}
+
+// allowedKeys are the keys handled by Loader, which are not in the types
+var allowedKeys = map[reflect.Type][]string{
+	reflect.TypeOf(DockerSwarm{}): {"include", "name"},
+	reflect.TypeOf(Service{}):     {"extends"},
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	errorLinePrefix = regexp.MustCompile(`^line (\d+): `)
+)
+
+// Validate checks the service file for unknown keys and values of wrong type, which are errors,
+// and for unsupported keys which are warnings. The content must be interpolated already.
+// Keys starting with "x-" are extensions and not checked.
+func Validate(file string, content []byte) (errs, warnings ValidationErrors) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil {
+		return lineError(file, err), nil
+	}
+
+	return ValidateNode(file, &root)
+}
+
+// ValidateNode is Validate for the parsed service file, the lines are of the node
+func ValidateNode(file string, root *yamlv3.Node) (errs, warnings ValidationErrors) {
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+
+	v := validator{file: file}
+	v.validate(root.Content[0], reflect.TypeOf(DockerSwarm{}), "")
+
+	return v.errors, v.warnings
+}
+
+type validator struct {
+	file     string
+	errors   ValidationErrors
+	warnings ValidationErrors
+}
+
+func (v *validator) addError(node *yamlv3.Node, keyPath, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if keyPath != "" {
+		message = keyPath + ": " + message
+	}
+
+	v.errors = append(v.errors, ValidationError{File: v.file, Line: node.Line, Message: message})
+}
+
+func (v *validator) validate(node *yamlv3.Node, t reflect.Type, keyPath string) {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// types with short and long syntax are checked as whole, the long syntax
+	// mapping is checked key by key first to report the line of the wrong key
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		if t.Kind() == reflect.Struct && node.Kind == yamlv3.MappingNode {
+			count := len(v.errors)
+			v.validateStruct(node, t, keyPath)
+			if len(v.errors) > count {
+				return
+			}
+		}
+
+		v.decode(node, t, keyPath)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			v.addError(node, keyPath, "must be a mapping")
+			return
+		}
+		v.validateStruct(node, t, keyPath)
+
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			v.addError(node, keyPath, "must be a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if key == "<<" {
+				continue
+			}
+			v.validate(node.Content[i+1], t.Elem(), joinKeyPath(keyPath, key))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yamlv3.SequenceNode {
+			v.addError(node, keyPath, "must be a list")
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(item, t.Elem(), fmt.Sprintf("%s[%d]", keyPath, i))
+		}
+
+	case reflect.Interface:
+
+	default:
+		v.decode(node, t, keyPath)
+	}
+}
+
+func (v *validator) validateStruct(node *yamlv3.Node, t reflect.Type, keyPath string) {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = t.Field(i).Type
+	}
+
+	for _, name := range allowedKeys[t] {
+		fields[name] = reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+
+		if key == "<<" || strings.HasPrefix(key, "x-") {
+			continue
+		}
+
+		fieldType, found := fields[key]
+		switch {
+		case found:
+			v.validate(valueNode, fieldType, joinKeyPath(keyPath, key))
+		case t == reflect.TypeOf(Service{}) && unsupportedServiceKeys[key]:
+			v.warnings = append(v.warnings, ValidationError{
+				File:    v.file,
+				Line:    keyNode.Line,
+				Message: fmt.Sprintf("%s: %q is not supported and is ignored", keyPath, key),
+			})
+		default:
+			v.addError(keyNode, keyPath, "unknown key %q", key)
+		}
+	}
+}
+
+// decode unmarshals the node alone into the type, like the service file is unmarshalled
+func (v *validator) decode(node *yamlv3.Node, t reflect.Type, keyPath string) {
+	content, err := yamlv3.Marshal(resolveAliases(node))
+	if err != nil {
+		v.addError(node, keyPath, "%s", err.Error())
+		return
+	}
+
+	err = yaml.Unmarshal(content, reflect.New(t).Interface())
+	if err == nil {
+		return
+	}
+
+	// line numbers in the errors are of the node alone
+	if typeError, ok := err.(*yaml.TypeError); ok {
+		for _, message := range typeError.Errors {
+			v.addError(node, keyPath, "%s", errorLinePrefix.ReplaceAllString(message, ""))
+		}
+		return
+	}
+
+	v.addError(node, keyPath, "%s", strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+// resolveAliases copies the node with aliases replaced by the anchored nodes,
+// since the node is marshalled without the anchors of the whole file
+func resolveAliases(node *yamlv3.Node) *yamlv3.Node {
+	if node.Kind == yamlv3.AliasNode {
+		return resolveAliases(node.Alias)
+	}
+
+	resolved := *node
+	resolved.Content = make([]*yamlv3.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		resolved.Content = append(resolved.Content, resolveAliases(child))
+	}
+
+	return &resolved
+}
+
+// lineError reports the parse or interpolation error of file like the validation errors,
+// with the line from the "line N:" prefix of the error
+func lineError(file string, err error) ValidationErrors {
+	message := strings.TrimPrefix(err.Error(), "yaml: ")
+
+	line := 0
+	if match := errorLinePrefix.FindStringSubmatch(message); match != nil {
+		line, _ = strconv.Atoi(match[1])
+		message = message[len(match[0]):]
+	}
+
+	return ValidationErrors{{File: file, Line: line, Message: message}}
+}
+
+func joinKeyPath(keyPath, key string) string {
+	if keyPath == "" {
+		return key
+	}
+	return keyPath + "." + key
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := `
+x-common: &common
+  restart: always
+services:
+  web:
+    <<: *common
+    image: nginx
+    ports:
+      - "80:80"
+      - target: 443
+        published: 8443
+    environment:
+      - KEY=value
+    command: nginx -g "daemon off;"
+    deploy:
+      replicas: 2
+      resources:
+        limits:
+          memory: 512M
+    labels: &labels
+      app: web
+    volumes:
+      - data:/data
+    ulimits:
+      nofile: 1024
+  api:
+    build: ./api
+    image: api
+    labels: *labels
+    extends: web
+    x-note: ignored
+volumes:
+  data:
+`
+	if errs, warnings := Validate("compose.yaml", []byte(valid)); len(errs) != 0 || len(warnings) != 0 {
+		t.Error("valid service file is rejected", errs, warnings)
+	}
+
+	invalid := `services:
+  web:
+    imgae: nginx
+    depends_on:
+      - db
+    deploy:
+      replicas: two
+    ports:
+      - target: 80
+        publish: 8080
+networks: default
+`
+	errs, warnings := Validate("compose.yaml", []byte(invalid))
+
+	// unsupported keys are ignored like docker stack deploy does
+	if len(warnings) != 1 || warnings[0].Error() != `compose.yaml:4: services.web: "depends_on" is not supported and is ignored` {
+		t.Error("unsupported key is not a warning", warnings)
+	}
+
+	expected := []string{
+		`compose.yaml:3: services.web: unknown key "imgae"`,
+		`compose.yaml:7: services.web.deploy.replicas: cannot unmarshal !!str ` + "`two`" + ` into uint64`,
+		`compose.yaml:10: services.web.ports[0]: unknown key "publish"`,
+		`compose.yaml:11: networks: must be a mapping`,
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatal("wrong validation errors", errs)
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(errs.Error(), e) {
+			t.Errorf("missing validation error %q in\n%s", e, errs.Error())
+		}
+	}
+
+	errs, _ = Validate("compose.yaml", []byte("services:\n  web:\n    image: [nginx\n"))
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "compose.yaml:2: ") {
+		t.Error("syntax error is not reported with its line", errs)
+	}
+}
+
+func TestLoadValidationLines(t *testing.T) {
+	files := mapFiles{
+		"compose.yaml": `# comment with ${
+services:
+  web:
+    image: app
+    command: ${COMMAND}
+    depends_on: [db]
+    deploy:
+      replicas: ${REPLICAS}
+    environment:
+      TOKEN: ${TOKEN:?token is required}
+`,
+		"compose.yaml.tmpl": `services:
+{{- range .services }}
+  {{ . }}:
+    image: app
+{{- end }}
+    imgae: app
+`,
+	}
+
+	variables := map[string]string{"COMMAND": "a\nb\nc", "REPLICAS": "many", "TOKEN": "secret"}
+
+	loader := Loader{
+		Files: files,
+		Lookup: func(name string) (string, bool) {
+			value, found := variables[name]
+			return value, found
+		},
+		Values: map[string]interface{}{"services": []string{"web"}},
+	}
+
+	_, err := loader.Load([]string{"compose.yaml"})
+	if err == nil || !strings.HasPrefix(err.Error(), "compose.yaml:8: services.web.deploy.replicas") {
+		t.Error("line of interpolated value is not of the source file", err)
+	}
+
+	variables["REPLICAS"] = "2"
+	delete(variables, "TOKEN")
+
+	_, err = loader.Load([]string{"compose.yaml"})
+	if err == nil || err.Error() != "compose.yaml:10: required variable TOKEN is missing a value: token is required" {
+		t.Error("interpolation error is not reported like validation errors", err)
+	}
+
+	variables["TOKEN"] = "secret"
+	if _, err := loader.Load([]string{"compose.yaml"}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(loader.Warnings) != 1 || !strings.HasPrefix(loader.Warnings[0].Error(), "compose.yaml:6:") {
+		t.Error("unsupported key is not a warning of Load", loader.Warnings)
+	}
+
+	_, err = loader.Load([]string{"compose.yaml.tmpl"})
+	if err == nil || !strings.HasPrefix(err.Error(), `compose.yaml.tmpl (rendered):4: services.web: unknown key "imgae"`) {
+		t.Error("line of template is not marked as rendered", err)
+	}
+}
+
+func TestLocateSpecErrors(t *testing.T) {
+	files := mapFiles{
+		"compose.yaml": `services:
+  web:
+    image: app
+    ports:
+      - "80:8080"
+  worker:
+    image: app
+`,
+		"compose.prod.yaml": `services:
+  web:
+    ports:
+      - "80:abc"
+  worker:
+    healthcheck:
+      interval: 5x
+`,
+	}
+
+	loader := Loader{Files: files}
+
+	for _, test := range []struct {
+		service  string
+		expected string
+	}{
+		{"web", "compose.prod.yaml:3: service web: "},
+		{"worker", "compose.prod.yaml:6: service worker: "},
+	} {
+		d, err := loader.Load([]string{"compose.yaml", "compose.prod.yaml"})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		for name := range d.Services {
+			if name != test.service {
+				delete(d.Services, name)
+			}
+		}
+
+		_, err = d.GetServiceSpec("app", map[string]string{DefaultNetwork: "app_default"}, Objects{})
+		if err == nil {
+			t.Fatalf("service %s must be invalid", test.service)
+		}
+
+		if located := loader.Locate(err); !strings.HasPrefix(located.Error(), test.expected) {
+			t.Errorf("error of service %s is not located: %v", test.service, located)
+		}
+	}
+
+	if err := loader.Locate(fmt.Errorf("other")); err.Error() != "other" {
+		t.Error("errors without key must not be changed", err)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+type Volume struct {
+	Name       string            `yaml:"name"` // defaults to the key
+	Driver     string            `yaml:"driver"`
+	DriverOpts map[string]string `yaml:"driver_opts"`
+	External   bool              `yaml:"external"`
+	Labels     Mapping           `yaml:"labels"`
+}
+
+// ServiceVolume is a mount of service, in short syntax like "data:/var/lib/data:ro"
+// or long syntax with type (volume, bind or tmpfs), source, target and options.
+type ServiceVolume struct {
+	Type     string              `yaml:"type"`
+	Source   string              `yaml:"source"`
+	Target   string              `yaml:"target"`
+	ReadOnly bool                `yaml:"read_only"`
+	Bind     *ServiceVolumeBind  `yaml:"bind"`
+	Volume   *ServiceVolumeOpts  `yaml:"volume"`
+	Tmpfs    *ServiceVolumeTmpfs `yaml:"tmpfs"`
+}
+
+type ServiceVolumeBind struct {
+	Propagation string `yaml:"propagation"` // like "rprivate"
+}
+
+type ServiceVolumeOpts struct {
+	NoCopy bool `yaml:"nocopy"`
+}
+
+type ServiceVolumeTmpfs struct {
+	Size string `yaml:"size"` // like "64m"
+	Mode uint32 `yaml:"mode"` // like 01777
+}
+
+func (v *ServiceVolume) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var short string
+	if err := unmarshal(&short); err == nil {
+		volume, err := parseShortVolume(short)
+		if err != nil {
+			return err
+		}
+
+		*v = volume
+		return nil
+	}
+
+	type long ServiceVolume
+	var l long
+	if err := unmarshal(&l); err != nil {
+		return err
+	}
+
+	*v = ServiceVolume(l)
+	if v.Type == "" {
+		v.Type = string(mount.TypeVolume)
+	}
+
+	return nil
+}
+
+// parseShortVolume parses [SOURCE:]TARGET[:MODE], where mode is
+// comma separated flags like "ro,nocopy" or bind propagation.
+func parseShortVolume(volume string) (ServiceVolume, error) {
+	tokens := strings.Split(volume, ":")
+
+	var v ServiceVolume
+
+	switch len(tokens) {
+	case 1:
+		// anonymous volume
+		v.Target = tokens[0]
+	case 2:
+		v.Source, v.Target = tokens[0], tokens[1]
+	case 3:
+		v.Source, v.Target = tokens[0], tokens[1]
+	default:
+		return ServiceVolume{}, fmt.Errorf("invalid volume %q", volume)
+	}
+
+	if v.Target == "" {
+		return ServiceVolume{}, fmt.Errorf("invalid volume %q: empty target", volume)
+	}
+
+	v.Type = string(mount.TypeVolume)
+	if isBindSource(v.Source) {
+		v.Type = string(mount.TypeBind)
+	}
+
+	if len(tokens) != 3 {
+		return v, nil
+	}
+
+	for _, flag := range strings.Split(tokens[2], ",") {
+		switch flag {
+		case "ro":
+			v.ReadOnly = true
+		case "rw":
+			v.ReadOnly = false
+		case "nocopy":
+			v.Volume = &ServiceVolumeOpts{NoCopy: true}
+		case "z", "Z":
+			// selinux labels are not supported by swarm mounts
+		case "shared", "rshared", "slave", "rslave", "private", "rprivate":
+			v.Bind = &ServiceVolumeBind{Propagation: flag}
+		default:
+			return ServiceVolume{}, fmt.Errorf("invalid volume %q: unknown mode %q", volume, flag)
+		}
+	}
+
+	return v, nil
+}
+
+// isBindSource checks for host paths, other sources are named volumes
+func isBindSource(source string) bool {
+	return strings.HasPrefix(source, ".") ||
+		strings.HasPrefix(source, "~") ||
+		strings.HasPrefix(source, "/")
+}
+
+// getMount makes the mount of service volume, named volumes must be defined in top level
+// volumes and are created by docker on the node with the driver and labels of the mount.
+// Relative bind mounts are paths on meltcd host, so they are only allowed with allowHostPaths.
+func (v ServiceVolume) getMount(appName string, volumes map[string]Volume, allowHostPaths bool) (mount.Mount, error) {
+	m := mount.Mount{
+		Type:     mount.Type(v.Type),
+		Source:   v.Source,
+		Target:   v.Target,
+		ReadOnly: v.ReadOnly,
+	}
+
+	if v.Target == "" {
+		return mount.Mount{}, fmt.Errorf("volume must have target")
+	}
+
+	switch m.Type {
+	case mount.TypeBind:
+		if v.Source == "" {
+			return mount.Mount{}, fmt.Errorf("bind volume %s must have source", v.Target)
+		}
+
+		if !strings.HasPrefix(v.Source, "/") && !allowHostPaths {
+			return mount.Mount{}, fmt.Errorf("bind volume %s has relative source %s, use absolute path or enable allow_host_paths", v.Target, v.Source)
+		}
+
+		absPath, err := normalizeFilePath(v.Source)
+		if err != nil {
+			return mount.Mount{}, err
+		}
+		m.Source = absPath
+
+		if v.Bind != nil && v.Bind.Propagation != "" {
+			m.BindOptions = &mount.BindOptions{Propagation: mount.Propagation(v.Bind.Propagation)}
+		}
+
+	case mount.TypeVolume:
+		noCopy := v.Volume != nil && v.Volume.NoCopy
+
+		if v.Source == "" {
+			// anonymous volume
+			if noCopy {
+				m.VolumeOptions = &mount.VolumeOptions{NoCopy: true}
+			}
+			break
+		}
+
+		def, found := volumes[v.Source]
+		if !found {
+			return mount.Mount{}, fmt.Errorf("volume %s is not defined in top level volumes", v.Source)
+		}
+
+		if def.Name != "" {
+			m.Source = def.Name
+		}
+
+		if def.External {
+			if noCopy {
+				m.VolumeOptions = &mount.VolumeOptions{NoCopy: true}
+			}
+			break
+		}
+
+		labels := make(map[string]string)
+		for k, val := range def.Labels {
+			labels[k] = val
+		}
+		labels["com.docker.stack.namespace"] = appName
+
+		m.VolumeOptions = &mount.VolumeOptions{
+			NoCopy: noCopy,
+			Labels: labels,
+		}
+
+		if def.Driver != "" || len(def.DriverOpts) != 0 {
+			m.VolumeOptions.DriverConfig = &mount.Driver{
+				Name:    def.Driver,
+				Options: def.DriverOpts,
+			}
+		}
+
+	case mount.TypeTmpfs:
+		if v.Source != "" {
+			return mount.Mount{}, fmt.Errorf("tmpfs volume %s can not have source", v.Target)
+		}
+
+		if v.Tmpfs != nil {
+			size, err := parseMemory(v.Tmpfs.Size)
+			if err != nil {
+				return mount.Mount{}, fmt.Errorf("tmpfs volume %s: %w", v.Target, err)
+			}
+
+			m.TmpfsOptions = &mount.TmpfsOptions{
+				SizeBytes: size,
+				Mode:      os.FileMode(v.Tmpfs.Mode),
+			}
+		}
+
+	default:
+		return mount.Mount{}, fmt.Errorf("invalid volume type %q, must be volume, bind or tmpfs", v.Type)
+	}
+
+	return m, nil
+}
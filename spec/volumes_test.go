@@ -0,0 +1,132 @@
+/*
+Copyright 2023 - PRESENT kunalsin9h
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"gopkg.in/yaml.v2"
+)
+
+func TestServiceVolumes(t *testing.T) {
+	var d DockerSwarm
+	err := yaml.Unmarshal([]byte(`
+services:
+  db:
+    image: postgres
+    volumes:
+      - data:/var/lib/postgresql/data:ro,nocopy
+      - /etc/ssl/certs:/certs:ro,rslave
+      - /cache
+      - type: tmpfs
+        target: /tmp
+        tmpfs:
+          size: 64m
+          mode: 01777
+      - type: volume
+        source: shared
+        target: /shared
+        read_only: true
+volumes:
+  data:
+    driver: local
+    driver_opts:
+      type: nfs
+    labels:
+      - com.example.backup=daily
+  shared:
+    external: true
+    name: shared_data
+`), &d)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	services, err := d.GetServiceSpec("app", map[string]string{DefaultNetwork: "net"}, Objects{})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	mounts := services[0].TaskTemplate.ContainerSpec.Mounts
+	if len(mounts) != 5 {
+		t.Fatalf("expected 5 mounts, got %d", len(mounts))
+	}
+
+	data := mounts[0]
+	if data.Type != mount.TypeVolume || data.Source != "data" || !data.ReadOnly {
+		t.Errorf("unexpected named volume: %+v", data)
+	}
+
+	if data.VolumeOptions == nil || !data.VolumeOptions.NoCopy ||
+		data.VolumeOptions.Labels["com.example.backup"] != "daily" ||
+		data.VolumeOptions.Labels["com.docker.stack.namespace"] != "app" {
+		t.Errorf("unexpected volume options: %+v", data.VolumeOptions)
+	}
+
+	if data.VolumeOptions.DriverConfig == nil || data.VolumeOptions.DriverConfig.Name != "local" ||
+		data.VolumeOptions.DriverConfig.Options["type"] != "nfs" {
+		t.Errorf("unexpected volume driver: %+v", data.VolumeOptions.DriverConfig)
+	}
+
+	certs := mounts[1]
+	if certs.Type != mount.TypeBind || certs.Source != "/etc/ssl/certs" || !certs.ReadOnly ||
+		certs.BindOptions == nil || certs.BindOptions.Propagation != mount.PropagationRSlave {
+		t.Errorf("unexpected bind mount: %+v", certs)
+	}
+
+	if mounts[2].Type != mount.TypeVolume || mounts[2].Source != "" || mounts[2].Target != "/cache" {
+		t.Errorf("unexpected anonymous volume: %+v", mounts[2])
+	}
+
+	tmpfs := mounts[3]
+	if tmpfs.Type != mount.TypeTmpfs || tmpfs.TmpfsOptions == nil ||
+		tmpfs.TmpfsOptions.SizeBytes != 64*1024*1024 || tmpfs.TmpfsOptions.Mode != 01777 {
+		t.Errorf("unexpected tmpfs mount: %+v", tmpfs)
+	}
+
+	shared := mounts[4]
+	if shared.Source != "shared_data" || !shared.ReadOnly || shared.VolumeOptions != nil {
+		t.Errorf("unexpected external volume: %+v", shared)
+	}
+}
+
+func TestInvalidServiceVolumes(t *testing.T) {
+	invalid := []string{
+		"data:/data:rx",
+		"a:b:c:d",
+		"data:",
+	}
+
+	for _, v := range invalid {
+		if _, err := parseShortVolume(v); err == nil {
+			t.Errorf("expected error for volume %q", v)
+		}
+	}
+
+	if _, err := (ServiceVolume{Type: "volume", Source: "missing", Target: "/data"}).getMount("app", nil, false); err == nil {
+		t.Error("expected error for volume not defined in top level volumes")
+	}
+
+	if _, err := (ServiceVolume{Type: "tmpfs", Source: "data", Target: "/data"}).getMount("app", nil, false); err == nil {
+		t.Error("expected error for tmpfs with source")
+	}
+
+	if _, err := (ServiceVolume{Type: "npipe", Target: "/data"}).getMount("app", nil, false); err == nil {
+		t.Error("expected error for unknown volume type")
+	}
+}
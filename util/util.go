@@ -38,6 +38,25 @@ func GetServer() string {
 	return server
 }
 
+// ParseKeyValues parses flags like "--var KEY=value" or "--param replicas=3",
+// kind names the flag in the error of a pair without "=".
+func ParseKeyValues(pairs []string, kind string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid %s %q, must be like key=value", kind, pair)
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
 func Info(text string, args ...any) { // nolint
 	fmt.Printf(text+"\n", args...)
 }